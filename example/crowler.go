@@ -24,13 +24,13 @@ func main() {
 	}
 
 	var title, charset string
-	err = goparse.Parse("%s<title>%s</title>%s", string(body)).InsertOnly(1, &title)
+	err = goparse.Search("<title>%s</title>", string(body)).Insert(&title)
 	if err != nil {
 		log.Fatalf("Parse failed err:%s", err)
 	}
 	fmt.Printf("title is %s\n", title)
 
-	err = goparse.Parse("%s<meta charset=\"%s\" />%s", string(body)).InsertOnly(1, &charset)
+	err = goparse.Search("<meta charset=\"%s\"", string(body)).Insert(&charset)
 	if err != nil {
 		log.Fatalf("Parse failed err:%s", err)
 	}
@@ -0,0 +1,380 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CompileBrace compiles the format written in the brace syntax of
+// the Python package parse, instead of the verbs of fmt.
+//
+//	{}          text
+//	{name}      text named name
+//	{:d}        integer
+//	{name:f}    floating-point number named name
+//	{:>10}      text padded to the width 10
+//	{:%Y-%m-%d} time written in strftime format
+//	{{ and }}   '{' and '}'
+//
+// The field is written as {name:[[fill]align][sign][0][width][.precision][type]}.
+// The types are
+//
+//	(none)           text, like %s
+//	l, w, W          letters, letters, digits and '_', and the others
+//	s, S             whitespaces and non-whitespaces
+//	d, D             integer like %d, and non-digits
+//	n                integer with thousands separators like 1,000
+//	b, o, x          integer in base 2, 8 and 16, the prefix like 0x is optional
+//	f, F, e, g       floating-point number
+//	%                percentage, 50% is read as 0.5
+//	ti, te, th, tc   time in ISO 8601, RFC 2822, HTTP log and ctime(3)
+//	ta, tg, ts, tt   time in US, global, syslog and time of day
+//	%...             time in strftime format like %Y-%m-%d
+//
+// Other verbs of goparse like {:ip}, {:v} and the custom verbs given by
+// WithVerb or RegisterVerb can be the type as well.
+// The result is the same as the format compiled by Compile.
+func CompileBrace(format string, opts ...Option) (*Format, error) {
+	return Compile(format, append(opts, WithBraceSyntax())...)
+}
+
+// MustCompileBrace is like CompileBrace but panics if the format cannot be compiled.
+func MustCompileBrace(format string, opts ...Option) *Format {
+	f, err := CompileBrace(format, opts...)
+	if err != nil {
+		panic(`goparse: CompileBrace(` + format + `): ` + err.Error())
+	}
+	return f
+}
+
+// WithBraceSyntax makes Compile read the format in the brace syntax.
+// See CompileBrace about the syntax.
+func WithBraceSyntax() Option {
+	return func(o *options) {
+		o.brace = true
+	}
+}
+
+// compileBrace compiles the format written in the brace syntax
+func compileBrace(format string, o *options) (*Format, error) {
+	b := &builder{format: format}
+	for i := 0; i < len(format); i++ {
+		switch format[i] {
+		case '{':
+			if strings.HasPrefix(format[i:], "{{") {
+				b.literal('{', i)
+				i++
+				continue
+			}
+			end := strings.IndexAny(format[i+1:], "{}")
+			if end == -1 || format[i+1+end] != '}' {
+				return nil, fmt.Errorf(
+					"invalid format(\"%s\"). '}' is missing", format)
+			}
+			end += i + 1
+
+			sp, err := parseField(format, i, end, o)
+			if err != nil {
+				return nil, err
+			}
+			if err := b.verb(sp, i); err != nil {
+				return nil, err
+			}
+			i = end
+		case '}':
+			if !strings.HasPrefix(format[i:], "}}") {
+				return nil, fmt.Errorf(
+					"invalid format(\"%s\"). single '}' must be written as '}}'", format)
+			}
+			b.literal('}', i)
+			i++
+		default:
+			b.literal(format[i], i)
+		}
+	}
+	return b.build(), nil
+}
+
+// parseField parses the field format[start:end+1] like {name:>10d}
+func parseField(format string, start, end int, o *options) (*spec, error) {
+	sp := &spec{width: -1, prec: -1}
+	sp.text = format[start : end+1]
+	sp.offset = end
+
+	field := format[start+1 : end]
+	name, fs, _ := strings.Cut(field, ":")
+	sp.name = name
+
+	invalid := func(msg string, args ...interface{}) error {
+		return fmt.Errorf("invalid format(\"%s\"). %s in %s",
+			format, fmt.Sprintf(msg, args...), sp.text)
+	}
+
+	// [[fill]align]
+	if _, size := utf8.DecodeRuneInString(fs); size < len(fs) && isAlign(fs[size]) {
+		sp.fill = fs[:size]
+		sp.minus, sp.center = fs[size] == '<', fs[size] == '^'
+		fs = fs[size+1:]
+	} else if fs != "" && isAlign(fs[0]) {
+		sp.minus, sp.center = fs[0] == '<', fs[0] == '^'
+		fs = fs[1:]
+	}
+	// [sign][0][width][.precision]
+	if fs != "" && (fs[0] == '+' || fs[0] == '-' || fs[0] == ' ') {
+		sp.plus, sp.space = fs[0] == '+', fs[0] == ' '
+		fs = fs[1:]
+	}
+	if strings.HasPrefix(fs, "0") {
+		sp.zero = true
+		fs = fs[1:]
+	}
+	var j int
+	sp.width, j = parseNumber(fs, 0)
+	if strings.HasPrefix(fs[j:], ".") {
+		sp.prec, j = parseNumber(fs, j+1)
+		if sp.prec == -1 {
+			return nil, invalid("precision is missing")
+		}
+	}
+	typ := fs[j:]
+
+	if err := braceType(sp, typ, o); err != nil {
+		return nil, invalid("%s", err)
+	}
+	if sp.verb != 's' {
+		// The precision is the max length of text,
+		// it's ignored by other types like parse does
+		sp.prec = -1
+	}
+	return sp, nil
+}
+
+func isAlign(c byte) bool {
+	return c == '<' || c == '>' || c == '^' || c == '='
+}
+
+// braceTimes are the layouts of the time types of the brace syntax
+var braceTimes = map[string]string{
+	"ti": time.RFC3339,
+	"te": time.RFC1123Z,
+	"th": "02/Jan/2006:15:04:05 -0700",
+	"tc": time.ANSIC,
+	"ta": "01/02/2006 03:04:05 PM",
+	"tg": "02/01/2006 15:04:05",
+	"ts": time.Stamp,
+	"tt": "15:04:05",
+}
+
+// braceVerbs are the types of the brace syntax which fmt doesn't have
+var braceVerbs = map[string]Verb{
+	"l": runeVerb(func(r rune) bool { return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' }),
+	"w": runeVerb(isWord),
+	"W": runeVerb(func(r rune) bool { return !isWord(r) }),
+	"s": runeVerb(unicode.IsSpace),
+	"S": runeVerb(func(r rune) bool { return !unicode.IsSpace(r) }),
+	"D": runeVerb(func(r rune) bool { return r < '0' || '9' < r }),
+	"n": groupedVerb{},
+	"%": percentVerb{},
+}
+
+// braceType sets the verb of type typ to sp
+func braceType(sp *spec, typ string, o *options) error {
+	switch {
+	case typ == "":
+		sp.verb = 's'
+	case typ == "d" || typ == "f" || typ == "F" || typ == "e" || typ == "g":
+		sp.verb = typ[0]
+	case typ == "o":
+		// %O reads 0o17 and 17, the prefix is optional
+		sp.verb = 'O'
+	case typ == "b" || typ == "x":
+		// The prefix like 0x is optional
+		sp.verb, sp.sharp = typ[0], true
+	case braceVerbs[typ] != nil:
+		sp.custom = braceVerbs[typ]
+	case braceTimes[typ] != "":
+		sp.verb, sp.arg = 'T', braceTimes[typ]
+	case strings.HasPrefix(typ, "%"):
+		layout, err := strftime(typ)
+		if err != nil {
+			return err
+		}
+		sp.verb, sp.arg = 'T', layout
+	default:
+		name, v := o.lookupVerb(typ)
+		if word := netWord(typ); word != "" && len(word) >= len(name) {
+			name, v = word, nil
+			sp.word = word
+		}
+		if name != "" && name == typ {
+			sp.custom = v
+			return nil
+		}
+		if len(typ) == 1 && builtin(typ[0]) && typ != "T" && typ != "D" {
+			sp.verb = typ[0]
+			return nil
+		}
+		return fmt.Errorf("unsupported type %q", typ)
+	}
+	return nil
+}
+
+// strftimes are the directives of strftime and the layouts of them
+var strftimes = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'j': "002",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'f': "000000", 'p': "PM",
+	'b': "Jan", 'h': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'z': "-0700", 'Z': "MST", '%': "%",
+}
+
+// strftime returns the layout of the time written in strftime format
+//
+//	strftime("%Y-%m-%d %H:%M") => "2006-01-02 15:04"
+func strftime(format string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return "", fmt.Errorf("directive is missing at the end of %q", format)
+		}
+		i++
+		layout, ok := strftimes[format[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported directive %%%c", format[i])
+		}
+		b.WriteString(layout)
+	}
+	return b.String(), nil
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// runeVerb consumes the longest run of runes which satisfy the function
+type runeVerb func(r rune) bool
+
+func (f runeVerb) Match(s, next string) (int, interface{}, error) {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !f(r) {
+			break
+		}
+		n += size
+	}
+	if n == 0 {
+		return 0, nil, strconv.ErrSyntax
+	}
+	return n, nil, nil
+}
+
+// groupedVerb is the integer with thousands separators like -1,234,567
+type groupedVerb struct{}
+
+func (groupedVerb) Match(s, next string) (int, interface{}, error) {
+	n := 0
+	if n < len(s) && (s[n] == '+' || s[n] == '-') {
+		n++
+	}
+	head := n
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	if n == head {
+		return 0, nil, strconv.ErrSyntax
+	}
+	if n-head <= 3 {
+		for n+4 <= len(s) && s[n] == ',' && allDigits(s[n+1:n+4]) &&
+			(n+4 == len(s) || !isDigit(s[n+4])) {
+			n += 4
+		}
+	}
+	i, err := strconv.ParseInt(strings.ReplaceAll(s[:n], ",", ""), 10, 64)
+	if err != nil {
+		return 0, nil, err
+	}
+	return n, i, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// format writes the integer v with thousands separators
+func (groupedVerb) format(v interface{}) (string, error) {
+	var s string
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(rv.Uint(), 10)
+	default:
+		return "", fmt.Errorf("expected integer, actual %T", v)
+	}
+
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i := 0; i < len(s); i++ {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), nil
+}
+
+// percentVerb is the percentage like 12.5%, which is 0.125
+type percentVerb struct{}
+
+func (percentVerb) Match(s, next string) (int, interface{}, error) {
+	n := 0
+	for n < len(s) && strings.IndexByte("0123456789+-.eE", s[n]) != -1 {
+		n++
+	}
+	if n == 0 || n == len(s) || s[n] != '%' {
+		return 0, nil, strconv.ErrSyntax
+	}
+	f, err := strconv.ParseFloat(s[:n], 64)
+	if err != nil {
+		return 0, nil, err
+	}
+	return n + 1, f / 100, nil
+}
+
+// format writes the number v as the percentage, 0.125 is written as 12.5%
+func (percentVerb) format(v interface{}) (string, error) {
+	var f float64
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f = rv.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(rv.Int())
+	default:
+		return "", fmt.Errorf("expected number, actual %T", v)
+	}
+	return strconv.FormatFloat(f*100, 'g', -1, 64) + "%", nil
+}
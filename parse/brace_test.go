@@ -0,0 +1,208 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileBrace(t *testing.T) {
+
+	t.Run("fields", func(t *testing.T) {
+		f, err := goparse.CompileBrace("Hello {}! I'm {name}, {age:d} years old")
+		assert.NoError(t, err)
+		r := f.Parse("Hello Yukari! I'm Sonoko Mizuki, 17 years old")
+
+		var who, name string
+		var age int
+		assert.NoError(t, r.Insert(&who, &name, &age))
+		assert.Equal(t, "Yukari", who)
+		assert.Equal(t, "Sonoko Mizuki", name)
+		assert.Equal(t, 17, age)
+		assert.Equal(t, []string{"name", "age"}, r.Names())
+		assert.Equal(t, "Hello {}! I'm {name}, {age:d} years old", f.String())
+	})
+
+	t.Run("types", func(t *testing.T) {
+		for _, c := range []struct {
+			format   string
+			str      string
+			expected interface{}
+		}{
+			{"{:d}", "-42", -42},
+			{"{:b}", "0b101", 5},
+			{"{:o}", "17", 15},
+			{"{:o}", "0o17", 15},
+			{"{:x}", "0xff", 255},
+			{"{:x}", "FF", 255},
+			{"{:f}", "1.5", 1.5},
+			{"{:.2f}", "1.5", 1.5},
+			{"{:e}", "1e+10", 1e10},
+			{"{:g}", "0.25", 0.25},
+			{"{:n}", "-1,234,567", -1234567},
+			{"{:n}", "1234", 1234},
+			{"{:%}", "12.5%", 0.125},
+			{"{:l}-{}", "abc-123", "abc"},
+			{"{:w} {}", "snake_case1 x", "snake_case1"},
+			{"{:W}x", " !x", " !"},
+			{"{:S} {}", "a,b c", "a,b"},
+			{"a{:s}b", "a \t b", " \t "},
+			{"{:D}1", "ab1", "ab"},
+			{"{:.3}", "abc", "abc"},
+			{"{:ip}", "192.0.2.1", netip.MustParseAddr("192.0.2.1")},
+			{"{:q}", `"a b"`, "a b"},
+			{"{:t}", "true", true},
+		} {
+			r := goparse.MustCompileBrace(c.format).Parse(c.str)
+			res, err := goparse.Get[interface{}](r, 0)
+			assert.NoErrorf(t, err, "Parse(%s, %s) failed", c.format, c.str)
+			assert.Equalf(t, c.expected, res, "Parse(%s, %s)", c.format, c.str)
+		}
+	})
+
+	t.Run("alignment", func(t *testing.T) {
+		for _, c := range []struct {
+			format   string
+			str      string
+			expected string
+		}{
+			{"[{:>10}]", "[     hello]", "hello"},
+			{"[{:<10}]", "[hello     ]", "hello"},
+			{"[{:^10}]", "[  hello   ]", "hello"},
+			{"[{:*^9}]", "[**hello**]", "hello"},
+			{"[{:>3}]", "[hello]", "hello"},
+		} {
+			var res string
+			err := goparse.MustCompileBrace(c.format).Parse(c.str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s, %s) failed", c.format, c.str)
+			assert.Equal(t, c.expected, res)
+		}
+
+		var n int
+		err := goparse.MustCompileBrace("[{:05d}]").Parse("[00042]").Insert(&n)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, n)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		for _, c := range []struct {
+			format   string
+			str      string
+			expected time.Time
+		}{
+			{"{:%Y-%m-%d}", "2019-04-01", time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{"at {:%H:%M:%S.%f}", "at 12:30:05.250000", time.Date(0, 1, 1, 12, 30, 5, 250000000, time.UTC)},
+			{"{:ti}", "2019-04-01T12:30:00Z", time.Date(2019, 4, 1, 12, 30, 0, 0, time.UTC)},
+			{"{:th}", "21/Nov/2011:10:21:36 +0000", time.Date(2011, 11, 21, 10, 21, 36, 0, time.UTC)},
+			{"{:tg}", "21/11/2011 10:21:36", time.Date(2011, 11, 21, 10, 21, 36, 0, time.UTC)},
+		} {
+			var res time.Time
+			err := goparse.MustCompileBrace(c.format).Parse(c.str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s, %s) failed", c.format, c.str)
+			assert.Truef(t, c.expected.Equal(res), "Parse(%s, %s) = %v", c.format, c.str, res)
+		}
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		var key string
+		var n int
+		err := goparse.MustCompileBrace("{{{}: {:d}}}").Parse("{id: 17}").Insert(&key, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, "id", key)
+		assert.Equal(t, 17, n)
+
+		var s string
+		err = goparse.MustCompileBrace("100% {}").Parse("100% sure").Insert(&s)
+		assert.NoError(t, err)
+		assert.Equal(t, "sure", s)
+	})
+
+	t.Run("sprint", func(t *testing.T) {
+		for _, c := range []struct {
+			format   string
+			value    interface{}
+			expected string
+		}{
+			{"{:o}", 15, "0o17"},
+			{"{:n}", 1234567, "1,234,567"},
+			{"{:n}", -1234, "-1,234"},
+			{"{:n}", uint8(12), "12"},
+			{"{:%}", 0.125, "12.5%"},
+			{"{:%}", 2, "200%"},
+			{"{:*^10}|", "hi", "****hi****|"},
+			{"[{:^7}]", "abc", "[  abc  ]"},
+			{"[{:-<5}]", "ab", "[ab---]"},
+			{"[{:0>5d}]", 42, "[00042]"},
+			{"[{:>8n}]", 1234, "[   1,234]"},
+			{"[{:・^6}]", "水樹", "[・・水樹・・]"},
+		} {
+			str, err := goparse.MustCompileBrace(c.format).Sprint(c.value)
+			assert.NoErrorf(t, err, "Sprint(%s, %v) failed", c.format, c.value)
+			assert.Equal(t, c.expected, str)
+		}
+
+		_, err := goparse.MustCompileBrace("{:n}").Sprint(1.5)
+		assert.Error(t, err)
+		_, err = goparse.MustCompileBrace("{:%}").Sprint("half")
+		assert.Error(t, err)
+	})
+
+	t.Run("option and custom verbs", func(t *testing.T) {
+		hex := goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+			if len(s) < 7 || s[0] != '#' {
+				return 0, nil, errors.New("not a color")
+			}
+			return 7, nil, nil
+		})
+		f, err := goparse.Compile("color={c:hexcolor}",
+			goparse.WithBraceSyntax(), goparse.WithVerb("hexcolor", hex))
+		assert.NoError(t, err)
+		var color string
+		assert.NoError(t, f.Parse("color=#ff8800").Get("c", &color))
+		assert.Equal(t, "#ff8800", color)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := goparse.MustCompileBrace("id={:d};").Parse("id=abc;")
+		var perr *goparse.ParseError
+		assert.True(t, errors.As(r.Insert(new(int)), &perr))
+		assert.Equal(t, "{:d}", perr.Verb)
+		assert.Equal(t, 3, perr.FormatOffset)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		for _, format := range []string{
+			"{", "}", "{:d", "a}b", "{a{b}", "{:zz}", "{:%Q}", "{:.d}",
+			"{a} {a}", "{}{}", "{:d}{:d}",
+		} {
+			_, err := goparse.CompileBrace(format)
+			assert.Errorf(t, err, "CompileBrace(%s) not failed want fail", format)
+		}
+	})
+}
+
+func ExampleCompileBrace() {
+	f := goparse.MustCompileBrace("{method} {path} {status:d} {size:n} [{at:%d/%m/%Y}]")
+	r := f.Parse("GET /index.html 200 1,024 [01/04/2019]")
+
+	var access struct {
+		Method string
+		Path   string
+		Status int
+		Size   int
+		At     time.Time
+	}
+	if err := r.Decode(&access); err != nil {
+		panic(err)
+	}
+	fmt.Println(access.Method, access.Path, access.Status, access.Size, access.At.Format("2006-01-02"))
+	// Output:
+	// GET /index.html 200 1024 2019-04-01
+}
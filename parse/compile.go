@@ -0,0 +1,332 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is a compiled format.
+// It is safe for concurrent use by multiple goroutines.
+type Format struct {
+	format string
+	prog   []instr
+	// names are the names of verbs in order, unnamed verb is ""
+	names []string
+}
+
+// instr is an instruction of the compiled program.
+// It is a literal text when spec is nil, otherwise a verb.
+type instr struct {
+	literal string
+	spec    *spec
+	// offset is the byte offset of the instruction in the format
+	offset int
+}
+
+// spec is a verb in the format like %d
+type spec struct {
+	verb byte
+	// name is the name of the placeholder like %{name}s
+	name string
+	// arg is the argument of the verb like the layout of %{name}{layout}T
+	arg string
+	// flags are '-', '+', ' ', '0' and '#' in fmt
+	minus, plus, space, zero, sharp bool
+	// width and prec are -1 when they are not specified
+	width, prec int
+	// text is the verb as written in the format
+	text string
+	// offset is the byte offset of the verb character in the format,
+	// it's the last character for the verb like %ip
+	offset int
+	// custom is the verb registered by RegisterVerb or WithVerb,
+	// verb is 0 then
+	custom Verb
+	// word is the name of the built-in verb longer than a character like "ip",
+	// verb is 0 then
+	word string
+	// fill is the padding of the width, it's " " when it's empty.
+	// center is true when the value is padded on both sides.
+	// They're given by the brace syntax like {:*^10}.
+	fill   string
+	center bool
+}
+
+// Compile parses format and returns the compiled Format.
+// The Format can be used to parse many strings without
+// interpreting the format again.
+// Custom verbs for the format can be given by WithVerb.
+func Compile(format string, opts ...Option) (*Format, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.err != nil {
+		return nil, fmt.Errorf("invalid option: %s", o.err)
+	}
+
+	if o.brace {
+		return compileBrace(format, o)
+	}
+
+	b := &builder{format: format}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.literal(format[i], i)
+			continue
+		}
+		if i+1 == len(format) {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). verb is missing at the end", format)
+		}
+		if format[i+1] == '%' {
+			b.literal('%', i)
+			i++
+			continue
+		}
+
+		sp, err := parseSpec(format, i, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.verb(sp, i); err != nil {
+			return nil, err
+		}
+		i += len(sp.text) - 1
+	}
+	return b.build(), nil
+}
+
+// builder builds the program of Format from literals and verbs
+type builder struct {
+	format string
+	prog   []instr
+	names  []string
+
+	lit       strings.Builder
+	litOffset int
+}
+
+// literal appends c at offset in the format to the literal text
+func (b *builder) literal(c byte, offset int) {
+	if b.lit.Len() == 0 {
+		b.litOffset = offset
+	}
+	b.lit.WriteByte(c)
+}
+
+// flush appends the literal text to the program
+func (b *builder) flush() {
+	if b.lit.Len() > 0 {
+		b.prog = append(b.prog, instr{literal: b.lit.String(), offset: b.litOffset})
+		b.lit.Reset()
+	}
+}
+
+// verb appends sp at offset in the format to the program
+func (b *builder) verb(sp *spec, offset int) error {
+	if sp.name != "" {
+		for _, name := range b.names {
+			if name == sp.name {
+				return fmt.Errorf(
+					"invalid format(\"%s\"). name %q is duplicated", b.format, sp.name)
+			}
+		}
+	}
+
+	b.flush()
+	if len(b.prog) > 0 && b.prog[len(b.prog)-1].spec != nil &&
+		ambiguous(b.prog[len(b.prog)-1].spec, sp) {
+		return fmt.Errorf(
+			"invalid format(\"%s\"). too ambiguous to invese format",
+			b.format)
+	}
+	b.prog = append(b.prog, instr{
+		spec:   sp,
+		offset: offset,
+	})
+	b.names = append(b.names, sp.name)
+	return nil
+}
+
+func (b *builder) build() *Format {
+	b.flush()
+	return &Format{format: b.format, prog: b.prog, names: b.names}
+}
+
+// parseSpec parses the verb starts at format[i], which is '%'
+//
+//	%d, %{name}d, %-10s, %.2f, %{name}08b, %ip, %[ip], %{name}{RFC3339}T
+//
+// The custom verb is written as %name or %[name]. The name starting with
+// a built-in verb like dms is only written as %[dms], %dms is %d and "ms".
+//
+// The verb %T can take the layout in braces, %{layout}T is the layout
+// not the name. The named %T must be written as %{name}{layout}T, and
+// the layout without any element of time like %{created}T is error.
+func parseSpec(format string, i int, o *options) (*spec, error) {
+	sp := &spec{width: -1, prec: -1}
+	j := i + 1
+	// braces is the number of {...} before flags
+	braces := 0
+	for braces < 2 && j < len(format) && format[j] == '{' {
+		end := strings.IndexByte(format[j:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). '}' is missing", format)
+		}
+		text := format[j+1 : j+end]
+		if text == "" {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). name is empty", format)
+		}
+		if braces == 0 {
+			sp.name = text
+		} else {
+			sp.arg = text
+		}
+		braces++
+		j += end + 1
+	}
+
+flags:
+	for ; j < len(format); j++ {
+		switch format[j] {
+		case '-':
+			sp.minus = true
+		case '+':
+			sp.plus = true
+		case ' ':
+			sp.space = true
+		case '0':
+			sp.zero = true
+		case '#':
+			sp.sharp = true
+		default:
+			break flags
+		}
+	}
+	sp.width, j = parseNumber(format, j)
+	if j < len(format) && format[j] == '.' {
+		sp.prec, j = parseNumber(format, j+1)
+		if sp.prec == -1 {
+			// "%.f" is same as "%.0f" in fmt
+			sp.prec = 0
+		}
+	}
+
+	if j == len(format) {
+		return nil, fmt.Errorf(
+			"invalid format(\"%s\"). verb is missing at the end", format)
+	}
+
+	var name string
+	var v Verb
+	end := j
+	switch {
+	case format[j] == '[':
+		k := strings.IndexByte(format[j:], ']')
+		if k == -1 {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). ']' is missing", format)
+		}
+		name = format[j+1 : j+k]
+		if _, ok := netVerbs[name]; ok {
+			sp.word = name
+		} else if v = o.verb(name); v == nil {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). unknown verb %%[%s]", format, name)
+		}
+		end = j + k + 1
+	case !builtin(format[j]):
+		name, v = o.lookupVerb(format[j:])
+		if word := netWord(format[j:]); word != "" && len(word) >= len(name) {
+			name, v = word, nil
+			sp.word = word
+		}
+		end = j + len(name)
+	}
+	if name != "" {
+		if sp.arg != "" {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). verb %s takes no argument", format, format[i:end])
+		}
+		sp.custom = v
+		sp.offset = end - 1
+		sp.text = format[i:end]
+		return sp, nil
+	}
+	if !builtin(format[j]) {
+		return nil, fmt.Errorf(
+			"invalid format(\"%s\"). unsupported verb %%%c", format, format[j])
+	}
+	sp.verb = format[j]
+	sp.offset = j
+	sp.text = format[i : j+1]
+
+	if sp.verb == 'T' {
+		if braces == 1 {
+			// %{layout}T
+			sp.name, sp.arg = "", sp.name
+		}
+		sp.arg = timeLayout(sp.arg)
+		if err := checkLayout(sp.arg); err != nil {
+			return nil, fmt.Errorf(
+				"invalid format(\"%s\"). %s in %s, the name of %%T is written as %%{name}{layout}T",
+				format, err, sp.text)
+		}
+	} else if sp.arg != "" {
+		return nil, fmt.Errorf(
+			"invalid format(\"%s\"). verb %s takes no argument", format, sp.text)
+	}
+	return sp, nil
+}
+
+// builtin reports whether c is the verb supported without registration
+func builtin(c byte) bool {
+	switch c {
+	case 's', 'd', 'b', 'o', 'O', 'x', 'X', 'c', 'U', 'q', 't',
+		'e', 'E', 'f', 'F', 'g', 'G', 'v', 'T', 'D':
+		return true
+	}
+	return false
+}
+
+// parseNumber parses digits starts at format[i] and returns the number
+// and the end of digits. The number is -1 when format[i] is not a digit.
+func parseNumber(format string, i int) (int, int) {
+	n := -1
+	for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+		if n == -1 {
+			n = 0
+		}
+		n = n*10 + int(format[i]-'0')
+	}
+	return n, i
+}
+
+// MustCompile is like Compile but panics if the format cannot be compiled.
+func MustCompile(format string, opts ...Option) *Format {
+	f, err := Compile(format, opts...)
+	if err != nil {
+		panic(`goparse: Compile(` + format + `): ` + err.Error())
+	}
+	return f
+}
+
+// String returns the source text used to compile the format.
+func (f *Format) String() string {
+	return f.format
+}
+
+// Parse parses str with the compiled format.
+// The whole str must match the format.
+func (f *Format) Parse(str string) Result {
+	m := newMatcher(f, str, true)
+	if !m.match(0, 0) {
+		return result{err: m.err.locate(str)}
+	}
+	return result{values: m.resolve(), names: f.names, start: 0, end: len(str)}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+
+	t.Run("compiled format parses many strings", func(t *testing.T) {
+		f, err := goparse.Compile("Hello %s, my number is %d")
+		assert.NoError(t, err)
+
+		for _, tt := range []struct {
+			name   string
+			number int
+		}{
+			{"iorin", 9753},
+			{"sonoko", 1},
+			{"yukari", 42},
+		} {
+			var name string
+			var number int
+			err := f.Parse(fmt.Sprintf("Hello %s, my number is %d", tt.name, tt.number)).
+				Insert(&name, &number)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.number, number)
+		}
+	})
+
+	t.Run("percent literal", func(t *testing.T) {
+		f, err := goparse.Compile("%d%% done")
+		assert.NoError(t, err)
+
+		var res int
+		err = f.Parse("42% done").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, res)
+	})
+
+	t.Run("String returns the format", func(t *testing.T) {
+		format := "Hello %s"
+		assert.Equal(t, format, goparse.MustCompile(format).String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		for _, tt := range []struct {
+			format   string
+			contains string
+		}{
+			{"%s%s%s", "ambiguous"},
+			{"Hello %", "missing"},
+			{"Hello %z", "unsupported verb"},
+		} {
+			_, err := goparse.Compile(tt.format)
+			assert.Errorf(t, err, "Compile(%s) not failed want fail", tt.format)
+			assert.Contains(t, err.Error(), tt.contains)
+		}
+	})
+
+	t.Run("MustCompile panics on invalid format", func(t *testing.T) {
+		assert.Panics(t, func() {
+			goparse.MustCompile("%d%d")
+		})
+	})
+
+	t.Run("compiled format is shared by goroutines", func(t *testing.T) {
+		f := goparse.MustCompile("worker-%d:%s")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var id int
+				var msg string
+				err := f.Parse(fmt.Sprintf("worker-%d:msg%d", i, i)).Insert(&id, &msg)
+				assert.NoError(t, err)
+				assert.Equal(t, i, id)
+				assert.Equal(t, fmt.Sprintf("msg%d", i), msg)
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func ExampleCompile() {
+	f := goparse.MustCompile("GET %s %d")
+	for _, line := range []string{"GET /index.html 200", "GET /missing 404"} {
+		var path string
+		var status int
+		_ = f.Parse(line).Insert(&path, &status)
+		fmt.Println(path, status)
+	}
+	// Output:
+	// /index.html 200
+	// /missing 404
+}
@@ -0,0 +1,343 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"database/sql"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// composite reports whether s is a slice or a map written by %v,
+// like "[1 2 3]" or "map[a:1 b:2]"
+func composite(s string) bool {
+	return len(s) >= 2 && s[0] == '[' && s[len(s)-1] == ']' ||
+		strings.HasPrefix(s, "map[") && strings.HasSuffix(s, "]")
+}
+
+// splitFields splits s by spaces which are not in brackets.
+//
+//	("1 [2 3] {4 5}") => ["1", "[2 3]", "{4 5}"]
+func splitFields(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var fields []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			if depth > 0 {
+				depth--
+			}
+		case ' ':
+			if depth == 0 {
+				fields = append(fields, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, s[start:])
+}
+
+// splitKey splits the entry of map written by %v at ':' which is not in brackets.
+//
+//	("a:[1 2]") => "a", "[1 2]"
+func splitKey(s string) (string, string, bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			if depth > 0 {
+				depth--
+			}
+		case ':':
+			if depth == 0 {
+				return s[:i], s[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// elemValue returns the value of s, which is an element of the slice,
+// the map or the struct parent written by %v, for the type t.
+// The element is a string when t is string, otherwise it's converted
+// like the value of %v.
+func elemValue(t reflect.Type, s string, parent value) value {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := parseValue(s)
+	if t.Kind() == reflect.String {
+		v = value{kind: reflect.String, value: s}
+	}
+	v.raw = s
+	v.verb = 'v'
+	v.plus, v.sharp = parent.plus, parent.sharp
+	return v
+}
+
+// assignComposite stores the slice or the map written by %v into d,
+// which is a slice, an array or a map.
+// Each element is stored in the same way as the value of %v.
+func assignComposite(d reflect.Value, src value) error {
+	s := src.text()
+	switch d.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !strings.HasPrefix(s, "[") {
+			return fmt.Errorf("type mismatch: %q is not a slice", s)
+		}
+		fields := splitFields(s[1 : len(s)-1])
+		elems := reflect.MakeSlice(reflect.SliceOf(d.Type().Elem()), len(fields), len(fields))
+		if d.Kind() == reflect.Array {
+			if len(fields) != d.Len() {
+				return fmt.Errorf("expected %d elements in %s, not %d",
+					d.Len(), d.Type().String(), len(fields))
+			}
+			elems = reflect.New(d.Type()).Elem()
+		}
+		for i, f := range fields {
+			if err := assignValue(elems.Index(i), elemValue(d.Type().Elem(), f, src)); err != nil {
+				return fmt.Errorf("element[%d] %s", i, err)
+			}
+		}
+		d.Set(elems)
+		return nil
+	case reflect.Map:
+		if !strings.HasPrefix(s, "map[") {
+			return fmt.Errorf("type mismatch: %q is not a map", s)
+		}
+		m := reflect.MakeMap(d.Type())
+		for _, f := range splitFields(s[len("map[") : len(s)-1]) {
+			k, v, ok := splitKey(f)
+			if !ok {
+				return fmt.Errorf("invalid entry of map %q", f)
+			}
+			key := reflect.New(d.Type().Key()).Elem()
+			if err := assignValue(key, elemValue(key.Type(), k, src)); err != nil {
+				return fmt.Errorf("key %s", err)
+			}
+			elem := reflect.New(d.Type().Elem()).Elem()
+			if err := assignValue(elem, elemValue(elem.Type(), v, src)); err != nil {
+				return fmt.Errorf("value of %s %s", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		d.Set(m)
+		return nil
+	}
+	return fmt.Errorf("type mismatch: expected *slice,*array,*map, actual %s", typeName(d))
+}
+
+// structText reports whether s is a struct written by %v, like "{a 1}" or "&{a 1}"
+func structText(s string) bool {
+	s = strings.TrimPrefix(s, "&")
+	return len(s) >= 2 && s[0] == '{' && s[len(s)-1] == '}'
+}
+
+// assignStruct stores the struct written by %v, %+v or %#v into d.
+// The fields written by fmt are separated by spaces, so that a field
+// can have several of them like the string "Alice Smith" or time.Time.
+// They are found by the types of the fields of d, the field takes as few
+// spaces as possible.
+func assignStruct(d reflect.Value, src value) error {
+	if d.Kind() != reflect.Struct {
+		return fmt.Errorf("type mismatch: expected *struct, actual %s", typeName(d))
+	}
+	fields := reflect.New(d.Type()).Elem()
+	if src.sharp {
+		if err := readGoStruct(fields, strings.TrimSpace(src.raw)); err != nil {
+			return err
+		}
+		d.Set(fields)
+		return nil
+	}
+
+	s := src.text()
+	if !structText(s) {
+		return fmt.Errorf("type mismatch: %q is not a struct", s)
+	}
+	s = strings.TrimPrefix(s, "&")
+	tokens := splitFields(s[1 : len(s)-1])
+	if src.plus {
+		if err := readNamed(fields, tokens, src); err != nil {
+			return err
+		}
+		d.Set(fields)
+		return nil
+	}
+
+	r := newStructReader(d.Type(), s[1:len(s)-1], tokens, src)
+	if !r.read(fields, 0, 0) {
+		return r.err
+	}
+	d.Set(fields)
+	return nil
+}
+
+// readNamed assigns the fields of the struct written by %+v like {Name:Hello Value:123}.
+// The fields are found by their names, so that they can be reordered or missing.
+// The token which doesn't start with the name of a field belongs to the previous field.
+func readNamed(d reflect.Value, tokens []string, src value) error {
+	t := d.Type()
+	var fields []reflect.StructField
+	var texts []string
+	for _, tok := range tokens {
+		if i := strings.IndexByte(tok, ':'); i > 0 {
+			if sf, ok := t.FieldByName(tok[:i]); ok && len(sf.Index) == 1 {
+				fields = append(fields, sf)
+				texts = append(texts, tok[i+1:])
+				continue
+			}
+		}
+		if len(texts) == 0 {
+			return fmt.Errorf("%s has no field of %q", t.String(), tok)
+		}
+		texts[len(texts)-1] += " " + tok
+	}
+
+	for i, sf := range fields {
+		if sf.PkgPath != "" {
+			return fmt.Errorf("target struct contains not exposed member %s", sf.Name)
+		}
+		if sf.Tag.Get(tagName) == "-" {
+			continue
+		}
+		if err := assignValue(d.Field(sf.Index[0]), elemValue(sf.Type, texts[i], src)); err != nil {
+			return fmt.Errorf("invalid type of %s.%s expected: %s, actual:%q err:%s",
+				t.String(), sf.Name, sf.Type.String(), texts[i], err)
+		}
+	}
+	return nil
+}
+
+// structReader assigns the fields of the struct written by %v
+type structReader struct {
+	typ reflect.Type
+	// text is the text in the braces, tokens are split from it at spaces
+	// and offsets are the byte offsets of tokens in text
+	text    string
+	tokens  []string
+	offsets []int
+	// src is the struct, its flags are given to the fields
+	src value
+	// failed is the set of (field, token) which can't be read
+	failed map[[2]int]bool
+	// err is the error of the field which failed first
+	err error
+}
+
+func newStructReader(typ reflect.Type, text string, tokens []string, src value) *structReader {
+	if len(tokens) == 0 && typ.NumField() == 1 {
+		// {} is the struct which has only an empty string
+		tokens = []string{""}
+	}
+	offsets := make([]int, len(tokens))
+	for i := 1; i < len(tokens); i++ {
+		offsets[i] = offsets[i-1] + len(tokens[i-1]) + 1
+	}
+	return &structReader{
+		typ:     typ,
+		text:    text,
+		tokens:  tokens,
+		offsets: offsets,
+		src:     src,
+		failed:  make(map[[2]int]bool),
+	}
+}
+
+// read assigns tokens from the token ti to the fields from the field fi
+// of the struct d, and it reports whether all tokens are assigned to all fields.
+// fmt writes every field in a token at least, even the empty string.
+func (r *structReader) read(d reflect.Value, fi, ti int) bool {
+	rest := r.typ.NumField() - fi
+	if rest == 0 && ti == len(r.tokens) {
+		return true
+	}
+	if rest > 0 && r.typ.Field(fi).PkgPath != "" {
+		r.fail(func() error {
+			return fmt.Errorf("target struct contains not exposed member %s", r.typ.Field(fi).Name)
+		})
+		return false
+	}
+	if rest == 0 || len(r.tokens)-ti < rest {
+		r.fail(func() error {
+			return fmt.Errorf("expected %d attributes in %s, not %d",
+				r.typ.NumField(), r.typ.String(), len(r.tokens))
+		})
+		return false
+	}
+	if r.failed[[2]int{fi, ti}] {
+		return false
+	}
+
+	sf := r.typ.Field(fi)
+	f := d.Field(fi)
+	if sf.Tag.Get(tagName) == "-" {
+		// The field is read but it's not stored
+		f = reflect.New(sf.Type).Elem()
+	}
+
+	// Each of the rest of fields takes a token at least
+	last := len(r.tokens) - (rest - 1)
+	first := ti + 1
+	if rest == 1 {
+		first = last
+	} else if singleToken(sf.Type) {
+		last = first
+	}
+	for n := first; n <= last; n++ {
+		text := r.text[r.offsets[ti] : r.offsets[n-1]+len(r.tokens[n-1])]
+		v := reflect.New(sf.Type).Elem()
+		if err := assignValue(v, elemValue(sf.Type, text, r.src)); err != nil {
+			r.fail(func() error {
+				return fmt.Errorf("invalid type of %s.%s expected: %s, actual:%q err:%s",
+					r.typ.String(), sf.Name, sf.Type.String(), text, err)
+			})
+			continue
+		}
+		f.Set(v)
+		if r.read(d, fi+1, n) {
+			return true
+		}
+	}
+	f.Set(reflect.Zero(sf.Type))
+	r.failed[[2]int{fi, ti}] = true
+	return false
+}
+
+// fail keeps the error of the first failure, the error is built only then
+func (r *structReader) fail(err func() error) {
+	if r.err == nil {
+		r.err = err()
+	}
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*fmt.Scanner)(nil)).Elem()
+	sqlScannerType      = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// singleToken reports whether the value of t is written in a token,
+// like the number which doesn't decode itself.
+func singleToken(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	if pt.Implements(textUnmarshalerType) || pt.Implements(scannerType) || pt.Implements(sqlScannerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
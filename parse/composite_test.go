@@ -0,0 +1,505 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_value_slice(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		format := "ids=%v;"
+		expected := []int{1, -2, 3}
+		var res []int
+		err := goparse.Parse(format, fmt.Sprintf(format, expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("various elements", func(t *testing.T) {
+		format := "%v|%v|%v|%v"
+		strs := []string{"a", "", "c"}
+		floats := []float64{1.5, -2, 3e10}
+		bools := []bool{true, false}
+		bytes := []byte("hi")
+		str := fmt.Sprintf(format, strs, floats, bools, bytes)
+
+		var resStrs []string
+		var resFloats []float64
+		var resBools []bool
+		var resBytes []byte
+		err := goparse.Parse(format, str).Insert(&resStrs, &resFloats, &resBools, &resBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, strs, resStrs)
+		assert.Equal(t, floats, resFloats)
+		assert.Equal(t, bools, resBools)
+		assert.Equal(t, bytes, resBytes)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		expected := [][]int{{1, 2}, {}, {3}}
+		var res [][]int
+		err := goparse.Parse("%v", fmt.Sprint(expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		expected := [3]uint8{1, 2, 255}
+		var res [3]uint8
+		err := goparse.Parse("rgb%v", fmt.Sprintf("rgb%v", expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+
+		var short [2]uint8
+		err = goparse.Parse("rgb%v", fmt.Sprintf("rgb%v", expected)).Insert(&short)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var res []int
+		err := goparse.Parse("%v", "[]").Insert(&res)
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+
+	t.Run("elements of named, pointer and decoder types", func(t *testing.T) {
+		type Level int
+		var levels []Level
+		var ptrs []*string
+		var addrs []netip.Addr
+		err := goparse.Parse("%v %v %v", "[1 2] [a b] [192.0.2.1 ::1]").Insert(&levels, &ptrs, &addrs)
+		assert.NoError(t, err)
+		assert.Equal(t, []Level{1, 2}, levels)
+		if assert.Len(t, ptrs, 2) {
+			assert.Equal(t, "a", *ptrs[0])
+			assert.Equal(t, "b", *ptrs[1])
+		}
+		assert.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.IPv6Loopback()}, addrs)
+	})
+
+	t.Run("element is not converted", func(t *testing.T) {
+		var res []int
+		err := goparse.Parse("%v", "[1 two 3]").Insert(&res)
+		assert.Error(t, err)
+
+		var overflow []int8
+		err = goparse.Parse("%v", "[1 200]").Insert(&overflow)
+		assert.Error(t, err)
+	})
+
+	t.Run("not a slice", func(t *testing.T) {
+		var res []int
+		err := goparse.Parse("%v", "map[a:1]").Insert(&res)
+		assert.Error(t, err)
+	})
+
+	t.Run("long text which doesn't match", func(t *testing.T) {
+		for _, format := range []string{"%v %v!", "%#v %#v!"} {
+			str := strings.Repeat("{a b} [1 2] ", 2000) + "!x"
+			r := goparse.Parse(format, str)
+			assert.Equal(t, 0, r.Len())
+			assert.Error(t, r.Insert())
+		}
+	})
+}
+
+func TestParse_value_map(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		format := "counts=%v;"
+		expected := map[string]int{"apple": 1, "banana": 20, "cherry": -3}
+		var res map[string]int
+		err := goparse.Parse(format, fmt.Sprintf(format, expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("keys and values of various types", func(t *testing.T) {
+		expected := map[int][]string{1: {"a", "b"}, 2: {}}
+		var res map[int][]string
+		err := goparse.Parse("%v", fmt.Sprint(expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+
+		var flags map[string]bool
+		err = goparse.Parse("%v", fmt.Sprint(map[string]bool{"debug": true, "quiet": false})).Insert(&flags)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"debug": true, "quiet": false}, flags)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var res map[string]interface{}
+		err := goparse.Parse("%v", "map[id:7 name:iori ok:true]").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": 7, "name": "iori", "ok": true}, res)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var res map[string]int
+		err := goparse.Parse("%v", "map[]").Insert(&res)
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"map[a]", "map[a:x]", "[1 2]"} {
+			var res map[string]int
+			err := goparse.Parse("%v", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+}
+
+func TestParse_value_struct_nested(t *testing.T) {
+
+	type name struct {
+		First, Last string
+	}
+	type person struct {
+		Name name
+		Age  int
+		Note string
+	}
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		for _, expected := range []person{
+			{Name: name{"Alice", "Smith"}, Age: 30, Note: "likes go"},
+			{Name: name{"Mary", "Ann Lee"}, Age: -1, Note: ""},
+			{Name: name{"", "O Neil"}, Age: 0, Note: "a {b} [c]"},
+		} {
+			str := fmt.Sprintf("person=%v;", expected)
+			var res person
+			err := goparse.Parse("person=%v;", str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s) failed", str)
+			assert.Equal(t, expected, res)
+		}
+	})
+
+	t.Run("string which has spaces", func(t *testing.T) {
+		type sample struct {
+			Name string
+			Age  int
+		}
+		var res sample
+		err := goparse.Parse("%v", "{Alice Smith 30}").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Name: "Alice Smith", Age: 30}, res)
+	})
+
+	t.Run("pointer to struct", func(t *testing.T) {
+		expected := &name{First: "x", Last: "y z"}
+		var res *name
+		err := goparse.Parse("%v", fmt.Sprintf("%v", expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		type sample struct {
+			Next  *int
+			Value interface{}
+			Count int
+		}
+		res := sample{Next: new(int)}
+		err := goparse.Parse("%v", fmt.Sprintf("%v", sample{Count: 2})).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Count: 2}, res)
+	})
+
+	t.Run("embedded struct", func(t *testing.T) {
+		type base struct {
+			ID   int
+			Kind string
+		}
+		type item struct {
+			base
+			Tags []string
+		}
+		type Item struct {
+			Base  base
+			Price float64
+		}
+		expected := Item{Base: base{ID: 1, Kind: "book"}, Price: 9.5}
+		var res Item
+		err := goparse.Parse("%v", fmt.Sprint(expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+
+		var unexported item
+		err = goparse.Parse("%v", fmt.Sprint(item{base: base{ID: 1}})).Insert(&unexported)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not exposed")
+	})
+
+	t.Run("skipped field is read but not stored", func(t *testing.T) {
+		type sample struct {
+			Name   string
+			Secret string `goparse:"-"`
+			Age    int
+		}
+		var res sample
+		err := goparse.Parse("%v", "{iori hidden 17}").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Name: "iori", Age: 17}, res)
+	})
+
+	t.Run("struct in slice and map", func(t *testing.T) {
+		expected := map[string][]name{"team": {{"a", "b"}, {"c", "d e"}}}
+		var res map[string][]name
+		err := goparse.Parse("%v", fmt.Sprint(expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("struct is followed by other verbs", func(t *testing.T) {
+		var res name
+		var n int
+		err := goparse.Parse("%v %d", "{a b} 3").Insert(&res, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, name{"a", "b"}, res)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var res interface{}
+		err := goparse.Parse("%v", "{a {b 1}}").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", []interface{}{"b", int64(1)}}, res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		type sample struct {
+			Count int
+			Ok    bool
+		}
+		for _, str := range []string{"{one true}", "{1 yes}", "{1 true 2}", "[1 true]", "{1}"} {
+			var res sample
+			err := goparse.Parse("%v", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+
+	t.Run("every field is written", func(t *testing.T) {
+		type pair struct {
+			A string
+			B int
+		}
+		var p pair
+		err := goparse.Parse("%v", "{x y}").Insert(&p)
+		assert.Error(t, err)
+
+		type triple struct {
+			A string
+			B int
+			C bool
+		}
+		var tr triple
+		err = goparse.Parse("%v", "{x 1 y}").Insert(&tr)
+		assert.Error(t, err)
+
+		err = goparse.Parse("%v", fmt.Sprintf("%v", pair{"", 1})).Insert(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, pair{"", 1}, p)
+
+		var single struct{ A string }
+		err = goparse.Parse("%v", fmt.Sprintf("%v", single)).Insert(&single)
+		assert.NoError(t, err)
+		assert.Equal(t, "", single.A)
+	})
+
+	t.Run("long struct", func(t *testing.T) {
+		type record struct {
+			A string
+			B int
+			C string
+			D bool
+			E string
+			F float64
+		}
+		words := strings.Repeat("ab cd ", 200)
+		expected := record{words, 1, words, true, words, 1.5}
+		str := fmt.Sprintf("%v", expected)
+		var res record
+		err := goparse.Parse("%v", str).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+
+		err = goparse.Parse("%v", str[:len(str)-1]+" x}").Insert(&res)
+		assert.Error(t, err)
+	})
+}
+
+func TestParse_value_plus(t *testing.T) {
+
+	type name struct {
+		First, Last string
+	}
+	type person struct {
+		Name  name
+		Age   int
+		Tags  []string
+		Score map[string]int
+	}
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		expected := person{
+			Name:  name{"Alice", "Mary Smith"},
+			Age:   30,
+			Tags:  []string{"a", "b"},
+			Score: map[string]int{"go": 10},
+		}
+		str := fmt.Sprintf("person=%+v;", expected)
+		var res person
+		err := goparse.Parse("person=%+v;", str).Insert(&res)
+		assert.NoErrorf(t, err, "Parse(%s) failed", str)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("reordered and missing fields", func(t *testing.T) {
+		var res person
+		err := goparse.Parse("%+v", "{Age:17 Name:{Last:Sonoko First:Mizuki}}").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: name{"Mizuki", "Sonoko"}, Age: 17}, res)
+	})
+
+	t.Run("pointer and slice of structs", func(t *testing.T) {
+		var p *name
+		err := goparse.Parse("%+v", fmt.Sprintf("%+v", &name{"a", "b"})).Insert(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, &name{"a", "b"}, p)
+
+		expected := []name{{"a", "b"}, {"c d", ""}}
+		var res []name
+		err = goparse.Parse("%+v", fmt.Sprintf("%+v", expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"{Height:180}", "{Age:old}", "{Name:x}"} {
+			var res person
+			err := goparse.Parse("%+v", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+
+		type secret struct {
+			key string
+		}
+		var res secret
+		err := goparse.Parse("%+v", "{key:abc}").Insert(&res)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not exposed")
+	})
+}
+
+type Item struct {
+	Name  string
+	Price float64
+	Count uint
+	Tags  []string
+	Attrs map[string]interface{}
+	Next  *Item
+	Ok    bool
+}
+
+func TestParse_value_sharp(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		expected := Item{
+			Name:  `say "hi", {friend}`,
+			Price: 1.5,
+			Count: 3,
+			Tags:  []string{"a b", "c,d"},
+			Attrs: map[string]interface{}{"color": "red", "size": 2},
+			Ok:    true,
+		}
+		str := fmt.Sprintf("item=%#v;", expected)
+		var res Item
+		err := goparse.Parse("item=%#v;", str).Insert(&res)
+		assert.NoErrorf(t, err, "Parse(%s) failed", str)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("slices, maps and scalars", func(t *testing.T) {
+		ints := []int{1, -2, 3}
+		bytes := []byte("hi")
+		m := map[string][]int{"a:b": {1}, "c": nil}
+		arr := [2]bool{true, false}
+		s := "quoted \"text\""
+		format := "%#v %#v %#v %#v %#v"
+		str := fmt.Sprintf(format, ints, bytes, m, arr, s)
+
+		var resInts []int
+		var resBytes []byte
+		var resM map[string][]int
+		var resArr [2]bool
+		var resS string
+		err := goparse.Parse(format, str).Insert(&resInts, &resBytes, &resM, &resArr, &resS)
+		assert.NoErrorf(t, err, "Parse(%s) failed", str)
+		assert.Equal(t, ints, resInts)
+		assert.Equal(t, bytes, resBytes)
+		assert.Equal(t, m, resM)
+		assert.Equal(t, arr, resArr)
+		assert.Equal(t, s, resS)
+	})
+
+	t.Run("anonymous struct and nil", func(t *testing.T) {
+		expected := struct {
+			A *int
+			B interface{}
+			C []string
+		}{}
+		var res struct {
+			A *int
+			B interface{}
+			C []string
+		}
+		res.A = new(int)
+		err := goparse.Parse("%#v", fmt.Sprintf("%#v", expected)).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var s, n interface{}
+		err := goparse.Parse("%#v=%#v", `"key"=42`).Insert(&s, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, "key", s)
+		assert.Equal(t, 42, n)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{
+			`goparse_test.Item{Height:1}`,
+			`goparse_test.Item{Name:hello}`,
+			`goparse_test.Item{Count:-1}`,
+			`goparse_test.Item{Name:"a"`,
+		} {
+			var res Item
+			err := goparse.Parse("%#v", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+}
+
+func ExampleParse_slice() {
+	var ids []int
+	var tags map[string]string
+	str := "ids=[3 1 4] tags=map[env:prod team:core]"
+	_ = goparse.Parse("ids=%v tags=%v", str).Insert(&ids, &tags)
+	fmt.Println(ids)
+	fmt.Println(tags["env"], tags["team"])
+	// Output:
+	// [3 1 4]
+	// prod core
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Verb is a custom verb like %ip, which is registered by RegisterVerb or WithVerb.
+type Verb interface {
+	// Match returns the length of the prefix of s the verb consumes and its value.
+	// next is the literal text following the verb in the format, it's empty
+	// when the verb is followed by another verb or is the end of the format.
+	// Match returns error when s doesn't start with the text of the verb.
+	// The value is the consumed text when v is nil.
+	Match(s, next string) (n int, v interface{}, err error)
+}
+
+// VerbFunc is an adapter to use the function as Verb.
+type VerbFunc func(s, next string) (int, interface{}, error)
+
+// Match calls f(s, next).
+func (f VerbFunc) Match(s, next string) (int, interface{}, error) {
+	return f(s, next)
+}
+
+var (
+	verbsMu sync.RWMutex
+	verbs   = make(map[string]Verb)
+)
+
+// RegisterVerb makes the verb available by the name in every format
+// compiled after it, like %ip for RegisterVerb("ip", v).
+// The name consists of ASCII letters and it can't be a built-in verb like "d" or "ip".
+// The verb is written as %name or %[name] in the format. The name starting
+// with a built-in verb like "dms" is only written as %[dms], so that the verb
+// doesn't change the meaning of formats like "took %dms".
+// When names of verbs overlap, the longest one is used, so that "%ipv6"
+// is the verb "ipv6" rather than "ip" followed by "v6".
+// RegisterVerb panics if the name is invalid, v is nil or
+// it's called twice for the same name.
+func RegisterVerb(name string, v Verb) {
+	if err := checkVerb(name, v); err != nil {
+		panic("goparse: RegisterVerb: " + err.Error())
+	}
+	verbsMu.Lock()
+	defer verbsMu.Unlock()
+	if _, dup := verbs[name]; dup {
+		panic("goparse: RegisterVerb called twice for verb " + name)
+	}
+	verbs[name] = v
+}
+
+// checkVerb returns error when the verb can't be registered
+func checkVerb(name string, v Verb) error {
+	if v == nil {
+		return fmt.Errorf("verb %s is nil", name)
+	}
+	if name == "" {
+		return fmt.Errorf("name of verb is empty")
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return fmt.Errorf("invalid name of verb %q", name)
+		}
+	}
+	if _, ok := netVerbs[name]; ok || len(name) == 1 && builtin(name[0]) {
+		return fmt.Errorf("verb %s is built-in", name)
+	}
+	return nil
+}
+
+// Option configures Compile.
+type Option func(*options)
+
+type options struct {
+	verbs map[string]Verb
+	// brace is true when the format is written in the brace syntax
+	brace bool
+	err   error
+}
+
+// WithVerb makes the verb available by the name only in the compiled format.
+// It takes precedence over the verb registered by RegisterVerb with the same name.
+func WithVerb(name string, v Verb) Option {
+	return func(o *options) {
+		if err := checkVerb(name, v); err != nil {
+			if o.err == nil {
+				o.err = err
+			}
+			return
+		}
+		if o.verbs == nil {
+			o.verbs = make(map[string]Verb)
+		}
+		o.verbs[name] = v
+	}
+}
+
+// verb returns the custom verb of the name, or nil when it's not found
+func (o *options) verb(name string) Verb {
+	if v, ok := o.verbs[name]; ok {
+		return v
+	}
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	return verbs[name]
+}
+
+// lookupVerb returns the longest custom verb at the head of s
+func (o *options) lookupVerb(s string) (string, Verb) {
+	var name string
+	var verb Verb
+	for n, v := range o.verbs {
+		if len(n) > len(name) && strings.HasPrefix(s, n) {
+			name, verb = n, v
+		}
+	}
+
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	for n, v := range verbs {
+		if len(n) > len(name) && strings.HasPrefix(s, n) {
+			name, verb = n, v
+		}
+	}
+	return name, verb
+}
+
+// scanCustom yields the text which the custom verb consumes
+func (sp *spec) scanCustom(str, next string, yield func(n int, v value) bool) (bool, error) {
+	n, v, err := sp.custom.Match(str, next)
+	if err != nil {
+		return false, err
+	}
+	if n < 0 || n > len(str) {
+		return false, fmt.Errorf("verb %s consumed %d bytes of %d", sp.text, n, len(str))
+	}
+	if v == nil {
+		v = str[:n]
+	}
+	return yield(n, customValue(v)), nil
+}
+
+// customValue returns the value of v returned by the custom verb.
+// The value of the basic kind is converted to the type built-in verbs use,
+// so that it can be assigned to any type of the kind.
+func customValue(v interface{}) value {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return value{kind: reflect.String, value: rv.String()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		if int64(int(n)) == n {
+			return value{kind: reflect.Int, value: int(n)}
+		}
+		return value{kind: reflect.Int64, value: n}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value{kind: reflect.Uint64, value: rv.Uint()}
+	case reflect.Bool:
+		return value{kind: reflect.Bool, value: rv.Bool()}
+	case reflect.Float32, reflect.Float64:
+		return value{kind: reflect.Float64, value: rv.Float()}
+	}
+	return value{kind: reflect.Interface, value: v}
+}
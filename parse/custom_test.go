@@ -0,0 +1,203 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+// uuid matches the text like 123e4567-e89b-12d3-a456-426614174000
+var uuid = goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+	const n = 36
+	if len(s) < n {
+		return 0, nil, errors.New("too short for uuid")
+	}
+	for i := 0; i < n; i++ {
+		c := s[i]
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return 0, nil, errors.New("invalid uuid")
+			}
+		case !strings.ContainsRune("0123456789abcdef", rune(c)):
+			return 0, nil, errors.New("invalid uuid")
+		}
+	}
+	return n, nil, nil
+})
+
+// addr matches the IP address until the following literal
+var addrVerb = goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+	n := len(s)
+	if next != "" {
+		if i := strings.Index(s, next); i != -1 {
+			n = i
+		}
+	}
+	addr, err := netip.ParseAddr(s[:n])
+	return n, addr, err
+})
+
+func init() {
+	goparse.RegisterVerb("uuid", uuid)
+	goparse.RegisterVerb("addr", addrVerb)
+}
+
+func TestRegisterVerb(t *testing.T) {
+
+	t.Run("registered verbs", func(t *testing.T) {
+		var id string
+		var addr netip.Addr
+		format := "id=%uuid from=%addr;"
+		str := "id=123e4567-e89b-12d3-a456-426614174000 from=192.0.2.1;"
+		err := goparse.Parse(format, str).Insert(&id, &addr)
+		assert.NoError(t, err)
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", id)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+	})
+
+	t.Run("custom verbs are adjacent to other verbs", func(t *testing.T) {
+		var id, rest string
+		err := goparse.Parse("%uuid%s", "123e4567-e89b-12d3-a456-426614174000.json").
+			Insert(&id, &rest)
+		assert.NoError(t, err)
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", id)
+		assert.Equal(t, ".json", rest)
+	})
+
+	t.Run("named custom verb", func(t *testing.T) {
+		var addr netip.Addr
+		err := goparse.Parse("from=%{src}addr", "from=::1").Get("src", &addr)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.IPv6Loopback(), addr)
+	})
+
+	t.Run("the value is not matched", func(t *testing.T) {
+		var addr netip.Addr
+		err := goparse.Parse("from=%addr;", "from=localhost;").Insert(&addr)
+		var perr *goparse.ParseError
+		if assert.True(t, errors.As(err, &perr)) {
+			assert.Equal(t, goparse.InvalidValue, perr.Kind)
+			assert.Equal(t, "%addr", perr.Verb)
+		}
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		assert.Panics(t, func() { goparse.RegisterVerb("addr", addrVerb) })
+		assert.Panics(t, func() { goparse.RegisterVerb("d", addrVerb) })
+		assert.Panics(t, func() { goparse.RegisterVerb("ip", addrVerb) })
+		assert.Panics(t, func() { goparse.RegisterVerb("a-b", addrVerb) })
+		assert.Panics(t, func() { goparse.RegisterVerb("", addrVerb) })
+		assert.Panics(t, func() { goparse.RegisterVerb("nil", nil) })
+	})
+}
+
+func TestWithVerb(t *testing.T) {
+
+	upper := goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+		n := 0
+		for n < len(s) && 'A' <= s[n] && s[n] <= 'Z' {
+			n++
+		}
+		if n == 0 {
+			return 0, nil, errors.New("no upper case letter")
+		}
+		return n, nil, nil
+	})
+
+	t.Run("the verb is scoped to the format", func(t *testing.T) {
+		f, err := goparse.Compile("%upper%d", goparse.WithVerb("upper", upper))
+		assert.NoError(t, err)
+		var s string
+		var n int
+		assert.NoError(t, f.Parse("ABC123").Insert(&s, &n))
+		assert.Equal(t, "ABC", s)
+		assert.Equal(t, 123, n)
+
+		_, err = goparse.Compile("%upper%d")
+		assert.Error(t, err)
+	})
+
+	t.Run("the longest name is used", func(t *testing.T) {
+		port := goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+			var n uint16
+			_, err := fmt.Sscanf(s, "%d", &n)
+			return len(s), n, err
+		})
+		f := goparse.MustCompile("%addrport", goparse.WithVerb("addrport", port))
+		var n int
+		assert.NoError(t, f.Parse("8080").Insert(&n))
+		assert.Equal(t, 8080, n)
+	})
+
+	t.Run("it takes precedence over the registered verb", func(t *testing.T) {
+		f := goparse.MustCompile("%uuid", goparse.WithVerb("uuid", upper))
+		var s string
+		assert.NoError(t, f.Parse("ABC").Insert(&s))
+		assert.Equal(t, "ABC", s)
+	})
+
+	t.Run("the name in brackets", func(t *testing.T) {
+		f := goparse.MustCompile("%[upper]abc %[ip]", goparse.WithVerb("upper", upper))
+		var s string
+		var addr netip.Addr
+		assert.NoError(t, f.Parse("XYZabc ::1").Insert(&s, &addr))
+		assert.Equal(t, "XYZ", s)
+		assert.Equal(t, netip.IPv6Loopback(), addr)
+
+		var id string
+		err := goparse.Parse("id=%{id}[uuid];", "id=123e4567-e89b-12d3-a456-426614174000;").Get("id", &id)
+		assert.NoError(t, err)
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", id)
+	})
+
+	t.Run("the name starts with a built-in verb", func(t *testing.T) {
+		for _, name := range []string{"dms", "email", "date", "token", "float"} {
+			f, err := goparse.Compile("took %dms %["+name+"]", goparse.WithVerb(name, upper))
+			assert.NoErrorf(t, err, "WithVerb(%s) failed", name)
+			var n int
+			var s string
+			assert.NoError(t, f.Parse("took 12ms ABC").Insert(&n, &s))
+			assert.Equal(t, 12, n)
+			assert.Equal(t, "ABC", s)
+		}
+
+		f := goparse.MustCompileBrace("{:dms}", goparse.WithVerb("dms", upper))
+		var s string
+		assert.NoError(t, f.Parse("ABC").Insert(&s))
+		assert.Equal(t, "ABC", s)
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		_, err := goparse.Compile("%s", goparse.WithVerb("s", upper))
+		assert.Error(t, err)
+
+		for _, format := range []string{"%[upper", "%[]", "%[lower]", "%[1]d"} {
+			_, err = goparse.Compile(format, goparse.WithVerb("upper", upper))
+			assert.Errorf(t, err, "Compile(%s) not failed want fail", format)
+		}
+	})
+}
+
+func ExampleRegisterVerb() {
+	goparse.RegisterVerb("hexcolor", goparse.VerbFunc(func(s, next string) (int, interface{}, error) {
+		var r, g, b uint8
+		if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+			return 0, nil, err
+		}
+		return 7, [3]uint8{r, g, b}, nil
+	}))
+
+	var color [3]uint8
+	_ = goparse.Parse("color: %hexcolor;", "color: #ff8000;").Insert(&color)
+	fmt.Println(color)
+	// Output:
+	// [255 128 0]
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagName is the key of struct tag.
+//
+//	type Access struct {
+//		User   string `goparse:"user"`
+//		Status int    `goparse:"status"`
+//		Note   string `goparse:"-"`
+//	}
+const tagName = "goparse"
+
+// field is a field of struct which can be a target of values
+type field struct {
+	// name is the tag name, or the field name when it has no tag
+	name  string
+	index int
+	// exported is false when the field can't be set
+	exported bool
+}
+
+// structFields returns fields of struct t in order.
+// The fields tagged `goparse:"-"` are skipped.
+func structFields(t reflect.Type) []field {
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup(tagName); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{
+			name:     name,
+			index:    i,
+			exported: sf.PkgPath == "",
+		})
+	}
+	return fields
+}
+
+// fieldByName returns the field whose name is name.
+// The field name is compared case-insensitively when no field has exactly the name.
+func fieldByName(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// Unmarshal parses str with format and stores values into the struct v points to.
+// See Result.Decode about how values are mapped to fields.
+func Unmarshal(format, str string, v interface{}) error {
+	return Parse(format, str).Decode(v)
+}
+
+// Decode stores values into the struct v points to.
+//
+// The value of the named verb like %{user}s is stored into the field tagged
+// `goparse:"user"`, or the field named User, and it's ignored when
+// the struct has no such field.
+// The values of unnamed verbs are stored into the rest of fields in order.
+// The fields tagged `goparse:"-"` are skipped.
+func (r result) Decode(v interface{}) error {
+	// r.err is happened by parser
+	if r.err != nil {
+		return r.err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid destination: expected non-nil pointer to struct, actual %T", v)
+	}
+	rv = rv.Elem()
+	targets, err := verbFields(rv.Type(), r.names)
+	if err != nil {
+		return err
+	}
+
+	for i, sv := range r.values {
+		f := targets[i]
+		if f == nil {
+			continue
+		}
+		if !f.exported {
+			return fmt.Errorf("field %s of %s is not exposed",
+				rv.Type().Field(f.index).Name, rv.Type().String())
+		}
+		err := assign(rv.Field(f.index).Addr().Interface(), sv)
+		if err != nil {
+			return fmt.Errorf(`assign(src{kind:%s,%v} => %s.%s) failed err:%w`,
+				sv.kind.String(), sv.value, rv.Type().String(),
+				rv.Type().Field(f.index).Name, err)
+		}
+	}
+	return nil
+}
+
+// verbFields returns the field of struct t for each verb named names.
+// It's nil for the named verb which has no field.
+func verbFields(t reflect.Type, names []string) ([]*field, error) {
+	fields := structFields(t)
+
+	// The fields of named values are not used by unnamed values
+	used := make(map[int]bool)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if f, ok := fieldByName(fields, name); ok {
+			used[f.index] = true
+		}
+	}
+
+	targets := make([]*field, len(names))
+	next := 0
+	for i, name := range names {
+		if name != "" {
+			if f, ok := fieldByName(fields, name); ok {
+				targets[i] = &f
+			}
+			continue
+		}
+		for next < len(fields) && used[fields[next].index] {
+			next++
+		}
+		if next == len(fields) {
+			return nil, fmt.Errorf(
+				"expected %d fields in %s, but format has more values",
+				len(fields), t.String())
+		}
+		targets[i] = &fields[next]
+		next++
+	}
+	return targets, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshal(t *testing.T) {
+
+	t.Run("by tag", func(t *testing.T) {
+		type access struct {
+			User   string `goparse:"user"`
+			Path   string `goparse:"path"`
+			Status int    `goparse:"status"`
+		}
+		format := "%{user}s GET %{path}s %{status}d"
+		str := "iori GET /index.html 200"
+		var res access
+		err := goparse.Unmarshal(format, str, &res)
+		assert.NoError(t, err)
+		assert.Equal(t, access{User: "iori", Path: "/index.html", Status: 200}, res)
+	})
+
+	t.Run("by field name", func(t *testing.T) {
+		type access struct {
+			Status int
+			User   string
+		}
+		format := "%{user}s %{status}d"
+		str := "iori 404"
+		var res access
+		err := goparse.Unmarshal(format, str, &res)
+		assert.NoError(t, err)
+		assert.Equal(t, access{User: "iori", Status: 404}, res)
+	})
+
+	t.Run("by field order", func(t *testing.T) {
+		type sample struct {
+			Name  string
+			Value int
+			Ok    bool
+		}
+		format := "name=%s value=%d ok=%t"
+		str := "name=sonoko value=16 ok=true"
+		var res sample
+		err := goparse.Unmarshal(format, str, &res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Name: "sonoko", Value: 16, Ok: true}, res)
+	})
+
+	t.Run("named and unnamed verbs are mixed", func(t *testing.T) {
+		type sample struct {
+			Event string
+			User  string `goparse:"user"`
+			Count int
+		}
+		format := "user=%{user}s %s x%d"
+		str := "user=yukari login x3"
+		var res sample
+		err := goparse.Unmarshal(format, str, &res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Event: "login", User: "yukari", Count: 3}, res)
+	})
+
+	t.Run("skipped field", func(t *testing.T) {
+		type sample struct {
+			Name  string
+			Note  string `goparse:"-"`
+			Value int
+		}
+		var res sample
+		err := goparse.Unmarshal("%s=%d", "iori=17", &res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Name: "iori", Value: 17}, res)
+	})
+
+	t.Run("unknown name is ignored", func(t *testing.T) {
+		type sample struct {
+			User string
+		}
+		var res sample
+		err := goparse.Unmarshal("%{user}s %{id}d", "iori 17", &res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{User: "iori"}, res)
+	})
+
+	t.Run("Decode of Result", func(t *testing.T) {
+		type sample struct {
+			Name string
+		}
+		var res sample
+		err := goparse.MustCompile("Hello %s").Parse("Hello iori").Decode(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, "iori", res.Name)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		t.Run("unexported field", func(t *testing.T) {
+			type sample struct {
+				name string
+			}
+			var res sample
+			err := goparse.Unmarshal("Hello %s", "Hello iori", &res)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "not exposed")
+		})
+
+		t.Run("more values than fields", func(t *testing.T) {
+			type sample struct {
+				Name string
+			}
+			var res sample
+			err := goparse.Unmarshal("%s=%d", "iori=17", &res)
+			assert.Error(t, err)
+		})
+
+		t.Run("type mismatch", func(t *testing.T) {
+			type sample struct {
+				Name int
+			}
+			var res sample
+			err := goparse.Unmarshal("Hello %s", "Hello iori", &res)
+			assert.Error(t, err)
+		})
+
+		t.Run("not pointer to struct", func(t *testing.T) {
+			var res string
+			err := goparse.Unmarshal("Hello %s", "Hello iori", &res)
+			assert.Error(t, err)
+
+			type sample struct {
+				Name string
+			}
+			err = goparse.Unmarshal("Hello %s", "Hello iori", sample{})
+			assert.Error(t, err)
+		})
+
+		t.Run("parse failed", func(t *testing.T) {
+			type sample struct {
+				Value int
+			}
+			var res sample
+			err := goparse.Unmarshal("value=%d", "value=none", &res)
+			assert.Error(t, err)
+		})
+	})
+}
+
+func ExampleUnmarshal() {
+	type access struct {
+		Method string `goparse:"method"`
+		Path   string `goparse:"path"`
+		Status int    `goparse:"status"`
+	}
+	var res access
+	_ = goparse.Unmarshal("%{method}s %{path}s %{status}d", "GET /index.html 200", &res)
+	fmt.Printf("%+v\n", res)
+	// Output:
+	// {Method:GET Path:/index.html Status:200}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorKind is the kind of failure reported by ParseError
+type ErrorKind int
+
+const (
+	// LiteralMismatch means the literal text in the format is not found
+	LiteralMismatch ErrorKind = iota + 1
+	// InvalidValue means the text can't be read as the verb
+	InvalidValue
+	// TrailingInput means the input continues after the end of format
+	TrailingInput
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case LiteralMismatch:
+		return "literal mismatch"
+	case InvalidValue:
+		return "invalid value"
+	case TrailingInput:
+		return "trailing input"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// maxFound is the max length of ParseError.Found in bytes
+const maxFound = 32
+
+// maxSnippet is the max length of the text around the error in Snippet, in bytes
+const maxSnippet = 40
+
+// ParseError is returned when the input doesn't match the format.
+// It can be retrieved from Result.Insert with errors.As.
+type ParseError struct {
+	Kind ErrorKind
+
+	// FormatOffset is the byte offset of the literal or verb in the format
+	FormatOffset int
+	// Offset is the byte offset in the input where the failure happened
+	Offset int
+	// Line and Column are 1-based position of Offset.
+	// Column is counted in runes.
+	Line, Column int
+
+	// Verb is the verb being processed like "%d".
+	// It's empty when the failure happened on a literal.
+	Verb string
+	// Expected is the literal text expected at Offset
+	Expected string
+	// Found is the text found at Offset, truncated to a few dozen bytes
+	Found string
+
+	// Err is the underlying error, e.g. *strconv.NumError
+	Err error
+
+	input string
+}
+
+func (e *ParseError) Error() string {
+	var msg string
+	switch e.Kind {
+	case InvalidValue:
+		msg = fmt.Sprintf("invalid value %q for %s", e.Found, e.Verb)
+	case TrailingInput:
+		msg = fmt.Sprintf("expected end of input but found %q", e.Found)
+	default:
+		msg = fmt.Sprintf("expected %q but found %q", e.Expected, e.Found)
+	}
+	msg = fmt.Sprintf("goparse: %s at offset %d (line %d, column %d)",
+		msg, e.Offset, e.Line, e.Column)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Snippet returns the line of input around Offset and a caret pointing it.
+// Long lines are truncated with "...".
+//
+//	...port=8080 status=OK time=12ms
+//	                    ^
+func (e *ParseError) Snippet() string {
+	start := strings.LastIndexByte(e.input[:e.Offset], '\n') + 1
+	end := len(e.input)
+	if i := strings.IndexByte(e.input[e.Offset:], '\n'); i != -1 {
+		end = e.Offset + i
+	}
+
+	prefix, suffix := "", ""
+	if e.Offset-start > maxSnippet {
+		start = runeStart(e.input, e.Offset-maxSnippet)
+		prefix = "..."
+	}
+	if end-e.Offset > maxSnippet {
+		end = runeStart(e.input, e.Offset+maxSnippet)
+		suffix = "..."
+	}
+
+	line := strings.Replace(e.input[start:end], "\t", " ", -1)
+	caret := len(prefix) + utf8.RuneCountInString(e.input[start:e.Offset])
+	return prefix + line + suffix + "\n" + strings.Repeat(" ", caret) + "^"
+}
+
+// locate fills the position of the error in input
+func (e *ParseError) locate(input string) *ParseError {
+	e.input = input
+	e.Line = strings.Count(input[:e.Offset], "\n") + 1
+	lineStart := strings.LastIndexByte(input[:e.Offset], '\n') + 1
+	e.Column = utf8.RuneCountInString(input[lineStart:e.Offset]) + 1
+	return e
+}
+
+// truncate cuts s into maxFound bytes at most, on rune boundary
+func truncate(s string) string {
+	if len(s) <= maxFound {
+		return s
+	}
+	return s[:runeStart(s, maxFound)] + "..."
+}
+
+// runeStart returns the start of the rune which contains s[i]
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
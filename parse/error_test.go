@@ -0,0 +1,143 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseError(t *testing.T, format, str string) *goparse.ParseError {
+	t.Helper()
+	var res string
+	err := goparse.Parse(format, str).InsertOnly(0, &res)
+	var perr *goparse.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Parse(%s,%s) should return ParseError, but it's %v", format, str, err)
+	}
+	return perr
+}
+
+func TestParseError(t *testing.T) {
+
+	t.Run("literal mismatch", func(t *testing.T) {
+		perr := parseError(t, "Hello %s, i'm %s", "Hello Iori. i'm sonoko")
+		assert.Equal(t, goparse.LiteralMismatch, perr.Kind)
+		assert.Equal(t, 6, perr.FormatOffset)
+		assert.Equal(t, 6, perr.Offset)
+		assert.Equal(t, 1, perr.Line)
+		assert.Equal(t, 7, perr.Column)
+		assert.Equal(t, "%s", perr.Verb)
+		assert.Equal(t, ", i'm ", perr.Expected)
+		assert.Equal(t, "Iori. i'm sonoko", perr.Found)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		perr := parseError(t, "%s is %d years old", "Iori is twenty years old")
+		assert.Equal(t, goparse.InvalidValue, perr.Kind)
+		assert.Equal(t, 6, perr.FormatOffset)
+		assert.Equal(t, 8, perr.Offset)
+		assert.Equal(t, "%d", perr.Verb)
+		assert.Equal(t, "twenty", perr.Found)
+		assert.True(t, errors.Is(asError(perr), strconv.ErrSyntax))
+	})
+
+	t.Run("offset of the verb wider than a character", func(t *testing.T) {
+		perr := parseError(t, "%s is %5d years old", "Iori is twenty years old")
+		assert.Equal(t, goparse.InvalidValue, perr.Kind)
+		assert.Equal(t, 6, perr.FormatOffset)
+		assert.Equal(t, "%5d", perr.Verb)
+
+		perr = parseError(t, "> %ip", "> localhost")
+		assert.Equal(t, goparse.InvalidValue, perr.Kind)
+		assert.Equal(t, 2, perr.FormatOffset)
+		assert.Equal(t, "%ip", perr.Verb)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		perr := parseError(t, "[%d]", "[99999999999999999999]")
+		assert.Equal(t, goparse.InvalidValue, perr.Kind)
+		var nerr *strconv.NumError
+		assert.True(t, errors.As(asError(perr), &nerr))
+		assert.Equal(t, strconv.ErrRange, nerr.Err)
+	})
+
+	t.Run("trailing input", func(t *testing.T) {
+		perr := parseError(t, "Hello", "Hello World")
+		assert.Equal(t, goparse.TrailingInput, perr.Kind)
+		assert.Equal(t, 5, perr.Offset)
+		assert.Equal(t, " World", perr.Found)
+	})
+
+	t.Run("line and column of multi-line input", func(t *testing.T) {
+		perr := parseError(t, "name: %s\nage: %d\n", "name: 水樹素子\nage: unknown\n")
+		assert.Equal(t, goparse.InvalidValue, perr.Kind)
+		assert.Equal(t, 2, perr.Line)
+		assert.Equal(t, 6, perr.Column)
+	})
+
+	t.Run("the message doesn't contain the whole input", func(t *testing.T) {
+		body := "<html>" + strings.Repeat("<p>long body</p>", 100) + "</html>"
+		perr := parseError(t, "<html>%s<title>%s</title>%s", body)
+		assert.Equal(t, goparse.LiteralMismatch, perr.Kind)
+		assert.Equal(t, "<title>", perr.Expected)
+		assert.True(t, len(perr.Error()) < 200, perr.Error())
+	})
+}
+
+func asError(perr *goparse.ParseError) error {
+	return perr
+}
+
+func TestParseError_Snippet(t *testing.T) {
+
+	t.Run("short line", func(t *testing.T) {
+		perr := parseError(t, "port=%d status=%s", "port=80 state=OK")
+		assert.Equal(t,
+			"port=80 state=OK\n"+
+				"       ^", perr.Snippet())
+	})
+
+	t.Run("the line of the error", func(t *testing.T) {
+		perr := parseError(t, "name: %s\nage: %d", "name: iori\nage: ??")
+		assert.Equal(t,
+			"age: ??\n"+
+				"     ^", perr.Snippet())
+	})
+
+	t.Run("long line is truncated", func(t *testing.T) {
+		str := strings.Repeat("a", 100) + "=" + strings.Repeat("b", 100)
+		perr := parseError(t, "%s:%s", str)
+		snippet := perr.Snippet()
+		lines := strings.Split(snippet, "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "aaa"))
+		assert.True(t, strings.HasSuffix(lines[0], "..."))
+		assert.Equal(t, "^", lines[1])
+	})
+}
+
+func ExampleParseError() {
+	format := "Hello %s, my number is %d"
+	str := "Hello iorin, my number is nine"
+
+	var name string
+	var num int
+	err := goparse.Parse(format, str).Insert(&name, &num)
+
+	var perr *goparse.ParseError
+	if errors.As(err, &perr) {
+		fmt.Println(perr.Kind, perr.Verb)
+		fmt.Println(perr.Snippet())
+	}
+	// Output:
+	// invalid value %d
+	// Hello iorin, my number is nine
+	//                           ^
+}
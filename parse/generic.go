@@ -0,0 +1,62 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Get returns the i-th value of r as T, 0-index.
+// The value is converted in the same way as Result.InsertOnly.
+//
+//	n, err := Get[int](Parse("id=%d", "id=17"), 0) => 17
+func Get[T any](r Result, i int) (T, error) {
+	var v T
+	if i < 0 {
+		return v, fmt.Errorf("invalid index:%d, index must not be negative", i)
+	}
+	err := r.InsertOnly(uint(i), &v)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// MustGet is like Get but panics if the value cannot be converted to T.
+func MustGet[T any](r Result, i int) T {
+	v, err := Get[T](r, i)
+	if err != nil {
+		panic(`goparse: MustGet(` + fmt.Sprint(i) + `): ` + err.Error())
+	}
+	return v
+}
+
+// ParseInto parses str with format and returns the values as T.
+// When T is a struct, the values are stored into its fields as Unmarshal does.
+// Otherwise format should have only one verb. The struct which decodes itself
+// like time.Time, or the struct read by the only unnamed verb like %url,
+// is the value of the verb as well.
+//
+//	type user struct {
+//		Name string
+//		ID   int
+//	}
+//	u, err := ParseInto[user]("%s:%d", "iori:17") => user{"iori", 17}
+func ParseInto[T any](format, str string) (T, error) {
+	var v T
+	r := Parse(format, str)
+	var err error
+	if t := reflect.TypeOf(&v).Elem(); t.Kind() == reflect.Struct && !decodes(t) &&
+		(r.Len() != 1 || len(r.Names()) != 0) {
+		err = r.Decode(&v)
+	} else {
+		err = r.Insert(&v)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
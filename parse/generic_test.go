@@ -0,0 +1,141 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+
+	t.Run("working case", func(t *testing.T) {
+		r := goparse.Parse("%s took %D, %d bytes", "GET took 1.5s, 300 bytes")
+
+		method, err := goparse.Get[string](r, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "GET", method)
+
+		d, err := goparse.Get[time.Duration](r, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1500*time.Millisecond, d)
+
+		n, err := goparse.Get[uint16](r, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(300), n)
+
+		p, err := goparse.Get[*int](r, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 300, *p)
+	})
+
+	t.Run("invalid case", func(t *testing.T) {
+		r := goparse.Parse("%d", "300")
+		for _, i := range []int{-1, 1} {
+			_, err := goparse.Get[int](r, i)
+			assert.Errorf(t, err, "Get(%d) not failed want fail", i)
+		}
+
+		n, err := goparse.Get[int8](r, 0)
+		assert.Error(t, err)
+		assert.Equal(t, int8(0), n)
+
+		_, err = goparse.Get[bool](r, 0)
+		assert.Error(t, err)
+
+		_, err = goparse.Get[int](goparse.Parse("%d", "abc"), 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestMustGet(t *testing.T) {
+	r := goparse.Parse("%s=%d", "iori=17")
+	assert.Equal(t, "iori", goparse.MustGet[string](r, 0))
+	assert.Equal(t, 17, goparse.MustGet[int](r, 1))
+	assert.Panics(t, func() { goparse.MustGet[string](r, 1) })
+}
+
+func TestParseInto(t *testing.T) {
+
+	type user struct {
+		Name string `goparse:"name"`
+		ID   int
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		u, err := goparse.ParseInto[user]("%s:%d", "iori:17")
+		assert.NoError(t, err)
+		assert.Equal(t, user{"iori", 17}, u)
+
+		u, err = goparse.ParseInto[user]("id=%d name=%{name}s", "id=16 name=sonoko")
+		assert.NoError(t, err)
+		assert.Equal(t, user{"sonoko", 16}, u)
+
+		type name struct {
+			Name string `goparse:"name"`
+		}
+		n, err := goparse.ParseInto[name]("name=%{name}s", "name=iori")
+		assert.NoError(t, err)
+		assert.Equal(t, name{"iori"}, n)
+	})
+
+	t.Run("single value", func(t *testing.T) {
+		ids, err := goparse.ParseInto[[]int]("ids=%v", "ids=[1 2 3]")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, ids)
+	})
+
+	t.Run("struct as a value", func(t *testing.T) {
+		at, err := goparse.ParseInto[time.Time]("%T", "2019-04-01T12:30:00Z")
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2019, 4, 1, 12, 30, 0, 0, time.UTC).Equal(at))
+
+		at, err = goparse.ParseInto[time.Time]("at %{DateOnly}T;", "at 2019-04-01;")
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC).Equal(at))
+
+		addr, err := goparse.ParseInto[netip.Addr]("from %ip", "from 192.0.2.1")
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+
+		u, err := goparse.ParseInto[url.URL]("GET %url", "GET https://example.com/index.html")
+		assert.NoError(t, err)
+		assert.Equal(t, "/index.html", u.Path)
+
+		v, err := goparse.ParseInto[user]("user=%+v", "user={Name:iori ID:17}")
+		assert.NoError(t, err)
+		assert.Equal(t, user{"iori", 17}, v)
+	})
+
+	t.Run("invalid case", func(t *testing.T) {
+		u, err := goparse.ParseInto[user]("%s:%s", "iori:seventeen")
+		assert.Error(t, err)
+		assert.Equal(t, user{}, u)
+
+		_, err = goparse.ParseInto[int]("%d %d", "1 2")
+		assert.Error(t, err)
+	})
+}
+
+func ExampleParseInto() {
+	type access struct {
+		Method string
+		Path   string
+		Status int
+	}
+	a, err := goparse.ParseInto[access]("%s %s %d", "GET /index.html 200")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", a)
+	fmt.Println(goparse.MustGet[int](goparse.Parse("status=%d", "status=404"), 0))
+	// Output:
+	// {Method:GET Path:/index.html Status:200}
+	// 404
+}
@@ -0,0 +1,339 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// skipQuoted returns the index after the quoted text starts at str[i],
+// which is "...", `...` or '...'. It's -1 when the quote is not closed.
+func skipQuoted(str string, i int) int {
+	q := str[i]
+	for j := i + 1; j < len(str); j++ {
+		switch str[j] {
+		case '\\':
+			if q != '`' {
+				j++
+			}
+		case q:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// goLiteral returns the length of the value written by %#v at the head of str,
+// like main.sample{Name:"Hello"}, []int{1, 2}, "text" or 123.
+// It's 0 when the end of the value is not found.
+func goLiteral(str string) int {
+	depth := 0
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '"', '`', '\'':
+			j := skipQuoted(str, i)
+			if j == -1 {
+				return 0
+			}
+			if depth == 0 {
+				return j
+			}
+			i = j - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				return i
+			}
+			if depth == 0 && c != ']' &&
+				(i+1 == len(str) || (str[i+1] != '(' && str[i+1] != '{')) {
+				return i + 1
+			}
+		case ' ', ',':
+			if depth == 0 && !strings.HasSuffix(str[:i], "interface") &&
+				!strings.HasSuffix(str[:i], "struct") {
+				return i
+			}
+		}
+	}
+	if depth != 0 {
+		return 0
+	}
+	return len(str)
+}
+
+// parseGoValue returns the value of s written by %#v
+func parseGoValue(s string) value {
+	if v, err := goValue(s); err == nil {
+		if str, ok := v.(string); ok {
+			return value{kind: reflect.String, value: str}
+		}
+	}
+	return parseValue(s)
+}
+
+// indexGo returns the index of the first sep in s which is not in
+// brackets or quotes, it's -1 when s has no such sep.
+func indexGo(s string, sep byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '`', '\'':
+			if j := skipQuoted(s, i); j != -1 {
+				i = j - 1
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitGo splits s by sep which is not in brackets or quotes.
+//
+//	(`"a", []int{1, 2}`, ',') => [`"a"`, "[]int{1, 2}"]
+func splitGo(s string, sep byte) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var fields []string
+	for {
+		i := indexGo(s, sep)
+		if i == -1 {
+			return append(fields, strings.TrimSpace(s))
+		}
+		fields = append(fields, strings.TrimSpace(s[:i]))
+		s = s[i+1:]
+	}
+}
+
+// cutGo cuts s at the first sep which is not in brackets or quotes
+func cutGo(s string, sep byte) (string, string, bool) {
+	i := indexGo(s, sep)
+	if i == -1 {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+}
+
+// goBody returns the elements in braces of the composite literal s,
+// like `Name:"Hello", Value:123` of main.sample{Name:"Hello", Value:123}
+func goBody(s string) (string, error) {
+	var opens []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '`', '\'':
+			if j := skipQuoted(s, i); j != -1 {
+				i = j - 1
+			}
+		case '{':
+			opens = append(opens, i)
+		case '}':
+			if len(opens) == 0 {
+				break
+			}
+			open := opens[len(opens)-1]
+			opens = opens[:len(opens)-1]
+			if i == len(s)-1 && len(opens) == 0 {
+				return s[open+1 : i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%q is not composite literal", s)
+}
+
+// goValue returns the Go value of s written by %#v for interface{}.
+// The composite literal is returned as it is written.
+func goValue(s string) (interface{}, error) {
+	switch {
+	case s == "nil" || strings.HasSuffix(s, "(nil)"):
+		return nil, nil
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`"):
+		return strconv.Unquote(s)
+	case s == "true" || s == "false":
+		return s == "true", nil
+	}
+	if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+		if int64(int(n)) == n {
+			return int(n), nil
+		}
+		return n, nil
+	}
+	if n, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	if _, err := goBody(s); err == nil {
+		return s, nil
+	}
+	return nil, fmt.Errorf("%q is not Go syntax", s)
+}
+
+// assignGo stores the value written by %#v in Go syntax into d.
+func assignGo(d reflect.Value, src value) error {
+	return readGo(d, strings.TrimSpace(src.raw))
+}
+
+// readGo stores the value s written in Go syntax into d
+func readGo(d reflect.Value, s string) error {
+	if s == "nil" || strings.HasSuffix(s, "(nil)") {
+		switch d.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			d.Set(reflect.Zero(d.Type()))
+			return nil
+		}
+	}
+
+	switch d.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(d.Type().Elem())
+		if err := readGo(p.Elem(), strings.TrimPrefix(s, "&")); err != nil {
+			return err
+		}
+		d.Set(p)
+		return nil
+	case reflect.Interface:
+		v, err := goValue(s)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			d.Set(reflect.Zero(d.Type()))
+			return nil
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.Type().AssignableTo(d.Type()) {
+			return fmt.Errorf("type mismatch: %s doesn't implement %s",
+				rv.Type().String(), d.Type().String())
+		}
+		d.Set(rv)
+		return nil
+	case reflect.String:
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			return errors.Wrapf(err, "Unquote(%s) failed", s)
+		}
+		d.SetString(str)
+		return nil
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		d.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, d.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "ParseInt(\"%s\") failed", s)
+		}
+		d.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, d.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "ParseUint(\"%s\") failed", s)
+		}
+		d.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, d.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "ParseFloat(\"%s\") failed", s)
+		}
+		d.SetFloat(f)
+		return nil
+	case reflect.Struct:
+		return assignStruct(d, value{kind: reflect.Struct, raw: s, verb: 'v', sharp: true})
+	case reflect.Slice, reflect.Array:
+		body, err := goBody(s)
+		if err != nil {
+			return err
+		}
+		fields := splitGo(body, ',')
+		elems := reflect.MakeSlice(reflect.SliceOf(d.Type().Elem()), len(fields), len(fields))
+		if d.Kind() == reflect.Array {
+			if len(fields) != d.Len() {
+				return fmt.Errorf("expected %d elements in %s, not %d",
+					d.Len(), d.Type().String(), len(fields))
+			}
+			elems = reflect.New(d.Type()).Elem()
+		}
+		for i, f := range fields {
+			if err := readGo(elems.Index(i), f); err != nil {
+				return fmt.Errorf("element[%d] %s", i, err)
+			}
+		}
+		d.Set(elems)
+		return nil
+	case reflect.Map:
+		body, err := goBody(s)
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMap(d.Type())
+		for _, f := range splitGo(body, ',') {
+			k, v, ok := cutGo(f, ':')
+			if !ok {
+				return fmt.Errorf("invalid entry of map %q", f)
+			}
+			key := reflect.New(d.Type().Key()).Elem()
+			if err := readGo(key, k); err != nil {
+				return fmt.Errorf("key %s", err)
+			}
+			elem := reflect.New(d.Type().Elem()).Elem()
+			if err := readGo(elem, v); err != nil {
+				return fmt.Errorf("value of %s %s", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		d.Set(m)
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s for %%#v", d.Type().String())
+}
+
+// readGoStruct assigns the fields of the struct written by %#v
+// like main.sample{Name:"Hello", Value:123} by their names.
+func readGoStruct(d reflect.Value, s string) error {
+	body, err := goBody(strings.TrimPrefix(s, "&"))
+	if err != nil {
+		return err
+	}
+	t := d.Type()
+	for _, f := range splitGo(body, ',') {
+		name, v, ok := cutGo(f, ':')
+		if !ok {
+			return fmt.Errorf("invalid field %q", f)
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok || len(sf.Index) != 1 {
+			return fmt.Errorf("%s has no field %s", t.String(), name)
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("target struct contains not exposed member %s", sf.Name)
+		}
+		if sf.Tag.Get(tagName) == "-" {
+			continue
+		}
+		if err := readGo(d.Field(sf.Index[0]), v); err != nil {
+			return fmt.Errorf("invalid type of %s.%s expected: %s, actual:%q err:%s",
+				t.String(), sf.Name, sf.Type.String(), v, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// matcher runs the compiled program over str.
+// When a verb can consume str in several ways, matcher tries each of them
+// and backtracks until the rest of the program matches.
+type matcher struct {
+	format string
+	prog   []instr
+	names  []string
+	str    string
+	values []value
+
+	// anchored requires the program to match until the end of str,
+	// otherwise end is set to the position where the program ended.
+	anchored bool
+	end      int
+
+	// failed memorizes (pc, pos) which never match,
+	// because the result doesn't depend on how the position was reached.
+	failed map[[2]int]bool
+
+	// err is the failure at the furthest position of str.
+	// It is reported when the program doesn't match.
+	err    *ParseError
+	errPos int
+}
+
+func newMatcher(f *Format, str string, anchored bool) *matcher {
+	return &matcher{
+		format:   f.format,
+		prog:     f.prog,
+		names:    f.names,
+		str:      str,
+		anchored: anchored,
+		failed:   make(map[[2]int]bool),
+		errPos:   -1,
+	}
+}
+
+// fail records err happened at pos when it is the furthest one
+func (m *matcher) fail(pos int, err *ParseError) {
+	if pos > m.errPos {
+		err.Offset = pos
+		m.err = err
+		m.errPos = pos
+	}
+}
+
+// match reports whether the program from pc matches str from pos to the end
+func (m *matcher) match(pc, pos int) bool {
+	if pc == len(m.prog) {
+		if !m.anchored {
+			m.end = pos
+			return true
+		}
+		if pos != len(m.str) {
+			m.fail(pos, &ParseError{
+				Kind:         TrailingInput,
+				FormatOffset: len(m.format),
+				Found:        truncate(m.str[pos:]),
+			})
+			return false
+		}
+		return true
+	}
+
+	in := m.prog[pc]
+	if in.spec == nil {
+		rest := m.str[pos:]
+		if !strings.HasPrefix(rest, in.literal) {
+			m.fail(pos, &ParseError{
+				Kind:         LiteralMismatch,
+				FormatOffset: in.offset,
+				Expected:     in.literal,
+				Found:        truncate(rest),
+			})
+			return false
+		}
+		return m.match(pc+1, pos+len(in.literal))
+	}
+
+	key := [2]int{pc, pos}
+	if m.failed[key] {
+		return false
+	}
+
+	last := pc+1 == len(m.prog)
+	next := ""
+	if !last {
+		next = m.prog[pc+1].literal
+	}
+	ok, err := in.spec.scan(m.str[pos:], next, last, func(n int, v value) bool {
+		v.raw = m.str[pos : pos+n]
+		v.start = pos
+		v.verb = in.spec.verb
+		v.plus, v.sharp = in.spec.plus, in.spec.sharp
+		m.values = append(m.values, v)
+		if m.match(pc+1, pos+n) {
+			return true
+		}
+		m.values = m.values[:len(m.values)-1]
+		return false
+	})
+	if !ok && err == nil && m.errPos < pos {
+		// No candidate of the verb is found
+		err = strconv.ErrSyntax
+	}
+	if err != nil {
+		m.fail(pos, in.spec.error(err, next, m.str[pos:]))
+	}
+	if !ok {
+		m.failed[key] = true
+	}
+	return ok
+}
+
+// resolve converts the deferred values after the match.
+// The failure of the conversion is kept in the value as ParseError.
+func (m *matcher) resolve() []value {
+	i := 0
+	for pc, in := range m.prog {
+		if in.spec == nil {
+			continue
+		}
+		v := m.values[i]
+		if v.parse != nil {
+			nv := v.parse(v.value.(string))
+			nv.raw, nv.start, nv.verb = v.raw, v.start, v.verb
+			nv.plus, nv.sharp = v.plus, v.sharp
+			if nv.err != nil {
+				next := ""
+				if pc+1 < len(m.prog) {
+					next = m.prog[pc+1].literal
+				}
+				perr := in.spec.error(nv.err, next, m.str[v.start:])
+				perr.Offset = v.start
+				nv.err = perr.locate(m.str)
+			}
+			m.values[i] = nv
+		}
+		i++
+	}
+	return m.values
+}
+
+// notFound is returned by scan when the literal following the verb is not found
+type notFound struct {
+	literal string
+}
+
+func (e notFound) Error() string {
+	return fmt.Sprintf("%q is not found", e.literal)
+}
+
+// error returns ParseError of err happened in scan at the head of str
+func (sp *spec) error(err error, next, str string) *ParseError {
+	if nf, ok := err.(notFound); ok {
+		return &ParseError{
+			Kind:         LiteralMismatch,
+			FormatOffset: sp.offset + 1 - len(sp.text),
+			Verb:         sp.text,
+			Expected:     nf.literal,
+			Found:        truncate(str),
+		}
+	}
+
+	found, ferr := before(next, str)
+	if ferr != nil {
+		found = str
+	}
+	return &ParseError{
+		Kind:         InvalidValue,
+		FormatOffset: sp.offset + 1 - len(sp.text),
+		Verb:         sp.text,
+		Found:        truncate(found),
+		Err:          errors.Cause(err),
+	}
+}
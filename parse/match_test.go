@@ -0,0 +1,234 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_adjacentVerbs(t *testing.T) {
+
+	t.Run("%s%d", func(t *testing.T) {
+		format := "%s%d"
+		str := "abc42"
+		checkTestCase(t, str, format, "abc", 42)
+
+		var s string
+		var n int
+		err := goparse.Parse(format, str).Insert(&s, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", s)
+		assert.Equal(t, 42, n)
+	})
+
+	t.Run("id%d%s", func(t *testing.T) {
+		format := "id%d%s"
+		str := "id42xyz"
+		checkTestCase(t, str, format, 42, "xyz")
+
+		var n int
+		var s string
+		err := goparse.Parse(format, str).Insert(&n, &s)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, n)
+		assert.Equal(t, "xyz", s)
+	})
+
+	t.Run("%s%d%s", func(t *testing.T) {
+		format := "%s%d%s"
+		str := "abc42xyz"
+		checkTestCase(t, str, format, "abc", 42, "xyz")
+
+		var s1, s2 string
+		var n int
+		err := goparse.Parse(format, str).Insert(&s1, &n, &s2)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", s1)
+		assert.Equal(t, 42, n)
+		assert.Equal(t, "xyz", s2)
+	})
+
+	t.Run("%s%t", func(t *testing.T) {
+		format := "%s%t"
+		str := "enabledtrue"
+		checkTestCase(t, str, format, "enabled", true)
+
+		var s string
+		var b bool
+		err := goparse.Parse(format, str).Insert(&s, &b)
+		assert.NoError(t, err)
+		assert.Equal(t, "enabled", s)
+		assert.Equal(t, true, b)
+	})
+
+	t.Run("%d%t", func(t *testing.T) {
+		format := "%d%t"
+		str := "123false"
+		checkTestCase(t, str, format, 123, false)
+
+		var n int
+		var b bool
+		err := goparse.Parse(format, str).Insert(&n, &b)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, n)
+		assert.Equal(t, false, b)
+	})
+
+	t.Run("the same kind of verbs are ambiguous", func(t *testing.T) {
+		for _, format := range []string{"%s%s", "%s%v", "%d%d", "%d%f", "%b%o"} {
+			_, err := goparse.Compile(format)
+			assert.Errorf(t, err, "Compile(%s) not failed want fail", format)
+			assert.Contains(t, err.Error(), "ambiguous")
+		}
+	})
+}
+
+func TestParse_backtracking(t *testing.T) {
+
+	t.Run("the literal appears in the value", func(t *testing.T) {
+		format := "%s-%d"
+		str := "a-b-12"
+		checkTestCase(t, str, format, "a-b", 12)
+
+		var s string
+		var n int
+		err := goparse.Parse(format, str).Insert(&s, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, "a-b", s)
+		assert.Equal(t, 12, n)
+	})
+
+	t.Run("the number is followed by a digit", func(t *testing.T) {
+		format := "%d1"
+		str := "1231"
+		checkTestCase(t, str, format, 123)
+
+		var n int
+		err := goparse.Parse(format, str).Insert(&n)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, n)
+	})
+
+	t.Run("many verbs separated by the same literal", func(t *testing.T) {
+		format := "%s_%s_%s_%d"
+		str := "a_b_c_d_e_42"
+		checkTestCase(t, str, format, "a", "b", "c_d_e", 42)
+
+		var s1, s2, s3 string
+		var n int
+		err := goparse.Parse(format, str).Insert(&s1, &s2, &s3, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", s1)
+		assert.Equal(t, "b", s2)
+		assert.Equal(t, "c_d_e", s3)
+		assert.Equal(t, 42, n)
+	})
+
+	t.Run("the whole string must match", func(t *testing.T) {
+		var n int
+		err := goparse.Parse("%d yen", "100 yen and more").Insert(&n)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected end of input")
+	})
+}
+
+func TestParse_widthAndFlags(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		for _, tt := range []struct {
+			format string
+			args   []interface{}
+		}{
+			{"[%5d]", []interface{}{42}},
+			{"[%-5d]", []interface{}{42}},
+			{"[%05d]", []interface{}{-42}},
+			{"[%+d]", []interface{}{42}},
+			{"[% d]", []interface{}{42}},
+			{"[% d]", []interface{}{-42}},
+			{"[%.5d]", []interface{}{42}},
+			{"[%08b]", []interface{}{5}},
+			{"[%#b]", []interface{}{5}},
+			{"[%#o]", []interface{}{8}},
+			{"[%10s]", []interface{}{"iori"}},
+			{"[%-10s]", []interface{}{"iori"}},
+			{"[%.3s]", []interface{}{"sonoko"}},
+			{"[%.2f]", []interface{}{3.14159}},
+			{"[%8.3f]", []interface{}{-2.5}},
+			{"[%-8.1f]", []interface{}{2.25}},
+			{"[%.0f]", []interface{}{2.0}},
+			{"[%+.1f]", []interface{}{2.5}},
+			{"[%3d]", []interface{}{12345}},
+			{"[%6t]", []interface{}{true}},
+		} {
+			str := fmt.Sprintf(tt.format, tt.args...)
+			t.Logf("test case: Parse(%s,%s)", tt.format, str)
+
+			r := goparse.Parse(tt.format, str)
+			switch expected := tt.args[0].(type) {
+			case int:
+				var res int
+				assert.NoErrorf(t, r.Insert(&res), "Parse(%s,%s) failed", tt.format, str)
+				assert.Equal(t, expected, res)
+			case string:
+				var res string
+				assert.NoErrorf(t, r.Insert(&res), "Parse(%s,%s) failed", tt.format, str)
+				assert.Equal(t, str, fmt.Sprintf(tt.format, res))
+			case float64:
+				var res float64
+				assert.NoErrorf(t, r.Insert(&res), "Parse(%s,%s) failed", tt.format, str)
+				assert.Equal(t, str, fmt.Sprintf(tt.format, res))
+			case bool:
+				var res bool
+				assert.NoErrorf(t, r.Insert(&res), "Parse(%s,%s) failed", tt.format, str)
+				assert.Equal(t, expected, res)
+			}
+		}
+	})
+
+	t.Run("padded columns", func(t *testing.T) {
+		format := "%-8s|%5d|%8.2f"
+		str := fmt.Sprintf(format, "apple", 120, 1.5)
+		var name string
+		var count int
+		var price float64
+		err := goparse.Parse(format, str).Insert(&name, &count, &price)
+		assert.NoError(t, err)
+		assert.Equal(t, "apple", name)
+		assert.Equal(t, 120, count)
+		assert.Equal(t, 1.5, price)
+	})
+
+	t.Run("the width bounds adjacent verbs", func(t *testing.T) {
+		format := "%5d%5d%-6s%s"
+		str := fmt.Sprintf(format, 1, 23, "ab", "cd")
+		var n1, n2 int
+		var s1, s2 string
+		err := goparse.Parse(format, str).Insert(&n1, &n2, &s1, &s2)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n1)
+		assert.Equal(t, 23, n2)
+		assert.Equal(t, "ab", s1)
+		assert.Equal(t, "cd", s2)
+	})
+
+	t.Run("the precision bounds adjacent verbs", func(t *testing.T) {
+		format := "%.2f%d"
+		str := fmt.Sprintf(format, 3.14, 42)
+		var f float64
+		var n int
+		err := goparse.Parse(format, str).Insert(&f, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, 3.14, f)
+		assert.Equal(t, 42, n)
+	})
+
+	t.Run("the precision limits the length of string", func(t *testing.T) {
+		var s string
+		err := goparse.Parse("[%.3s]", "[sonoko]").Insert(&s)
+		assert.Error(t, err)
+	})
+}
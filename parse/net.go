@@ -0,0 +1,105 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// netVerb is the built-in verb of the network address like %ip
+type netVerb struct {
+	// charset is the characters the address can contain
+	charset string
+	// class is the class of the verb, see ambiguous
+	class class
+	parse func(s string) (interface{}, error)
+}
+
+const (
+	hexChars  = "0123456789abcdefABCDEF"
+	hostChars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-_"
+	urlChars  = hostChars + "~:/?#[]@!$&'()*+,;=%"
+)
+
+// netVerbs are the built-in verbs which have the name longer than a character
+var netVerbs = map[string]netVerb{
+	// ip is IPv4 or IPv6 address like 192.0.2.1 or 2001:db8::1
+	"ip": {charset: hexChars + ".:", class: classNumber, parse: func(s string) (interface{}, error) {
+		return netip.ParseAddr(s)
+	}},
+	// net is CIDR notation like 192.0.2.0/24
+	"net": {charset: hexChars + ".:/", class: classNumber, parse: func(s string) (interface{}, error) {
+		return netip.ParsePrefix(s)
+	}},
+	// mac is MAC address like 00:00:5e:00:53:01
+	"mac": {charset: hexChars + ":-.", class: classNumber, parse: func(s string) (interface{}, error) {
+		return net.ParseMAC(s)
+	}},
+	// hostport is host:port like example.com:80 or [2001:db8::1]:443
+	"hostport": {charset: hostChars + ":[]", class: classNumber, parse: parseHostPort},
+	// url is absolute URL like https://example.com/index.html
+	"url": {charset: urlChars, class: classText, parse: parseURL},
+}
+
+// netWord returns the name of the longest network verb at the head of s
+func netWord(s string) string {
+	word := ""
+	for name := range netVerbs {
+		if len(name) > len(word) && strings.HasPrefix(s, name) {
+			word = name
+		}
+	}
+	return word
+}
+
+// scanNet yields the longest address at the head of str and shorter ones
+func scanNet(nv netVerb, str, next string, last bool, yield func(n int, v value) bool) (bool, error) {
+	return scanToken(str, next, last, nv.charset, "", func(s string) (value, error) {
+		v, err := nv.parse(s)
+		if err != nil {
+			return value{}, err
+		}
+		if s, ok := v.(string); ok {
+			return value{kind: reflect.String, value: s}, nil
+		}
+		return value{kind: reflect.Interface, value: v}, nil
+	}, yield)
+}
+
+// parseHostPort returns netip.AddrPort when the host is IP address,
+// otherwise s itself.
+func parseHostPort(s string) (interface{}, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap, nil
+	}
+	if host == "" || strings.ContainsAny(host, ":[]") ||
+		strings.HasPrefix(host, ".") || strings.HasPrefix(host, "-") {
+		return nil, fmt.Errorf("invalid host %q", host)
+	}
+	return s, nil
+}
+
+// parseURL returns *url.URL of the absolute URL s
+func parseURL(s string) (interface{}, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || (u.Host == "" && u.Opaque == "" && u.Path == "") {
+		return nil, fmt.Errorf("%q is not absolute URL", s)
+	}
+	return u, nil
+}
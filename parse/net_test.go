@@ -0,0 +1,183 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_ip(t *testing.T) {
+
+	t.Run("IPv4 and IPv6", func(t *testing.T) {
+		for _, expected := range []string{"192.0.2.1", "2001:db8::1", "::1", "::ffff:192.0.2.1"} {
+			var res netip.Addr
+			err := goparse.Parse("from %ip to", "from "+expected+" to").Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s) failed", expected)
+			assert.Equal(t, netip.MustParseAddr(expected), res)
+		}
+	})
+
+	t.Run("the literal following the address is not unique", func(t *testing.T) {
+		var addr string
+		var port int
+		err := goparse.Parse("%ip:%d", "192.0.2.1:8080").Insert(&addr, &port)
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", addr)
+		assert.Equal(t, 8080, port)
+
+		var res netip.Addr
+		err = goparse.Parse("client is %ip.", "client is 192.0.2.1.").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.1"), res)
+	})
+
+	t.Run("net.IP", func(t *testing.T) {
+		var res net.IP
+		err := goparse.Parse("%ip", "2001:db8::1").Insert(&res)
+		assert.NoError(t, err)
+		assert.True(t, net.ParseIP("2001:db8::1").Equal(res))
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var res interface{}
+		err := goparse.Parse("%ip", "192.0.2.1").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.1"), res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"from 192.0.2.256 to", "from localhost to", "from  to"} {
+			var res netip.Addr
+			err := goparse.Parse("from %ip to", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		_, err := goparse.Compile("%ip%d")
+		assert.Error(t, err)
+	})
+}
+
+func TestParse_net(t *testing.T) {
+
+	t.Run("CIDR", func(t *testing.T) {
+		var p4, p6 netip.Prefix
+		err := goparse.Parse("allow %net,%net;", "allow 192.0.2.0/24,2001:db8::/32;").Insert(&p4, &p6)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParsePrefix("192.0.2.0/24"), p4)
+		assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), p6)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var res netip.Prefix
+		for _, str := range []string{"allow 192.0.2.0", "allow 192.0.2.0/33"} {
+			err := goparse.Parse("allow %net", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+}
+
+func TestParse_mac(t *testing.T) {
+
+	t.Run("MAC address", func(t *testing.T) {
+		for _, str := range []string{"00:00:5e:00:53:01", "00-00-5E-00-53-01", "0000.5e00.5301"} {
+			var res net.HardwareAddr
+			err := goparse.Parse("hw=%mac;", "hw="+str+";").Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s) failed", str)
+			assert.Equal(t, "00:00:5e:00:53:01", res.String())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var res net.HardwareAddr
+		err := goparse.Parse("hw=%mac;", "hw=00:00:5e;").Insert(&res)
+		assert.Error(t, err)
+	})
+}
+
+func TestParse_hostport(t *testing.T) {
+
+	t.Run("IP address and port", func(t *testing.T) {
+		for _, expected := range []string{"192.0.2.1:8080", "[2001:db8::1]:443"} {
+			var res netip.AddrPort
+			err := goparse.Parse("connect %hostport ok", "connect "+expected+" ok").Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s) failed", expected)
+			assert.Equal(t, netip.MustParseAddrPort(expected), res)
+		}
+	})
+
+	t.Run("host name and port", func(t *testing.T) {
+		var res string
+		err := goparse.Parse("connect %hostport:", "connect example.com:80:").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com:80", res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"connect example.com ok", "connect example.com:http ok", "connect example.com:65536 ok"} {
+			var res string
+			err := goparse.Parse("connect %hostport ok", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+}
+
+func TestParse_url(t *testing.T) {
+
+	t.Run("URL", func(t *testing.T) {
+		str := `"GET https://example.com/search?q=go&n=1#top HTTP/1.1"`
+		var res *url.URL
+		err := goparse.Parse(`"GET %url HTTP/1.1"`, str).Insert(&res)
+		assert.NoError(t, err)
+		if assert.NotNil(t, res) {
+			assert.Equal(t, "https", res.Scheme)
+			assert.Equal(t, "example.com", res.Host)
+			assert.Equal(t, "/search", res.Path)
+			assert.Equal(t, "go", res.Query().Get("q"))
+			assert.Equal(t, "top", res.Fragment)
+		}
+	})
+
+	t.Run("the URL is followed by a character of URL", func(t *testing.T) {
+		var res url.URL
+		err := goparse.Parse("(see %url)", "(see https://example.com/a)").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/a", res.String())
+
+		var s string
+		err = goparse.Parse("<%url>", "<mailto:iori@example.com>").Insert(&s)
+		assert.NoError(t, err)
+		assert.Equal(t, "mailto:iori@example.com", s)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"see /index.html", "see example.com", "see http://[::1"} {
+			var res *url.URL
+			err := goparse.Parse("see %url", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s) not failed want fail", str)
+		}
+	})
+}
+
+func ExampleParse_network() {
+	var client netip.Addr
+	var server netip.AddrPort
+	var ref *url.URL
+	str := "192.0.2.1 -> 198.51.100.7:443 https://example.com/index.html"
+	_ = goparse.Parse("%ip -> %hostport %url", str).Insert(&client, &server, &ref)
+	fmt.Println(client)
+	fmt.Println(server.Port())
+	fmt.Println(ref.Host)
+	// Output:
+	// 192.0.2.1
+	// 443
+	// example.com
+}
@@ -3,8 +3,12 @@
 package goparse
 
 import (
+	"database/sql"
+	"encoding"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,13 +16,44 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Result has two interface. and it's returned by Parse
+// Result is returned by Parse. It has values of verbs in the format.
 type Result interface {
 	// Insert insets format values to dest
 	Insert(dest ...interface{}) error
 
 	// Insert inserts a selected format value to dest, 0-index
 	InsertOnly(index uint, dest interface{}) error
+
+	// Get inserts the value of the named verb like %{name}s to dest
+	Get(name string, dest interface{}) error
+
+	// Names returns names of the named verbs in order of the format
+	Names() []string
+
+	// Map returns values of the named verbs by their names
+	Map() map[string]interface{}
+
+	// Decode stores values into the struct v points to
+	Decode(v interface{}) error
+
+	// Bounds returns the byte offsets of the matched text in the input.
+	// It's the whole input for Parse, and the found text for Search.
+	Bounds() (start, end int)
+
+	// Len returns the number of values, it's 0 when parsing failed
+	Len() int
+
+	// Kind returns the kind of the i-th value, 0-index
+	Kind(i int) reflect.Kind
+
+	// Value returns the i-th value as it is parsed, 0-index
+	Value(i int) interface{}
+
+	// Raw returns the text in the input which the i-th verb consumed
+	Raw(i int) string
+
+	// Span returns the byte offsets of Raw(i) in the input
+	Span(i int) (start, end int)
 }
 
 // parseString returns string before format
@@ -30,19 +65,26 @@ type Result interface {
 // If format not contains str in text before '%', returns err
 //  ( format="Soni%", str="MizukiSonoko") => [MizukiSonoko] not contains [Soni]
 func parseString(format, str string) (string, error) {
+	return before(strings.Split(format, "%")[0], str)
+}
+
+// before returns string before literal
+//  ( literal=" ", str= "a b c") => "a"
+//  ( literal="", str= "nnnn") => "nnnn"
+//  ( literal="or", str= "(yes)or(no)") => "(yes)"
+func before(literal, str string) (string, error) {
 
 	// This case is happened by %s is in end of a text.
-	if len(format) == 0 {
+	if len(literal) == 0 {
 		return str, nil
 	}
 
-	terms := strings.Split(format, "%")
-	i := strings.Index(str, terms[0])
+	i := strings.Index(str, literal)
 	if i == -1 {
 		return "", fmt.Errorf("[%s] not contains [%s]",
-			str, terms[0])
+			str, literal)
 	} else if i == 0 {
-		ni := strings.Index(str[1:], terms[0])
+		ni := strings.Index(str[1:], literal)
 		if ni == -1 {
 			return str[:i], nil
 		}
@@ -52,32 +94,38 @@ func parseString(format, str string) (string, error) {
 }
 
 // parseInteger returns number
-// (format="yen",str="123yen",base=10) => 123
-// (format="yen",str="10101yen",base=2) => 21
-func parseInteger(format, str string, base int) (int, error) {
-	/*
-		Note: this function never returns error.
-		 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-		 So str must contain format text before '%'
-	*/
-	s, _ := parseString(format, str)
-
-	// ToDo We should think about int64
+// (s="123",base=10) => 123
+// (s="10101",base=2) => 21
+// The value is int, or int64 and uint64 when it's out of range of int.
+func parseInteger(s string, base int) (value, error) {
 	i, err := strconv.ParseInt(s, base, 0)
-	if err != nil {
-		return 0, errors.Wrapf(err, "ParseInt(\"%s\",%d) failed", s, base)
+	if err == nil {
+		return value{kind: reflect.Int, value: int(i)}, nil
+	}
+	if i, err64 := strconv.ParseInt(s, base, 64); err64 == nil {
+		return value{kind: reflect.Int64, value: i}, nil
 	}
-	return int(i), nil
+	if u, erru := strconv.ParseUint(strings.TrimPrefix(s, "+"), base, 64); erru == nil {
+		return value{kind: reflect.Uint64, value: u}, nil
+	}
+	return value{}, errors.Wrapf(err, "ParseInt(\"%s\",%d) failed", s, base)
 }
 
-func parseBool(format, str string) (bool, error) {
-	/*
-		Note: this function never returns error.
-		 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-		 So str must contain format text before '%'
-	*/
-	s, _ := parseString(format, str)
+// bigInteger returns the value of s like parseInteger.
+// The number out of the range of 64 bits is *big.Int with the error.
+func bigInteger(s string, base int) value {
+	v, err := parseInteger(s, base)
+	if err == nil {
+		return v
+	}
+	n, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return value{kind: reflect.String, value: s, err: err}
+	}
+	return value{kind: reflect.Interface, value: n, err: err}
+}
 
+func parseBool(s string) (bool, error) {
 	b, err := strconv.ParseBool(s)
 	if err != nil {
 		return false, errors.Wrapf(err, "ParseBool(%s) failed", s)
@@ -85,14 +133,7 @@ func parseBool(format, str string) (bool, error) {
 	return b, nil
 }
 
-func parseFloat(format, str string) (float64, error) {
-	/*
-		Note: this function never returns error.
-		 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-		 So str must contain format text before '%'
-	*/
-	s, _ := parseString(format, str)
-
+func parseFloat(s string) (float64, error) {
 	f, err := strconv.ParseFloat(s, 0)
 	if err != nil {
 		return 0, errors.Wrapf(err, "ParseFloat(%s) failed", s)
@@ -103,130 +144,400 @@ func parseFloat(format, str string) (float64, error) {
 type value struct {
 	kind  reflect.Kind
 	value interface{}
+	// raw is the text in the input which the verb consumed
+	raw string
+	// start is the byte offset of raw in the input
+	start int
+	// verb is the verb character which read the value like 'd'
+	verb byte
+	// plus and sharp are the flags of %+v and %#v
+	plus, sharp bool
+	// parse converts the text in value into the value after the whole
+	// format matched. It's set by the verb whose conversion is expensive
+	// like %v, not to convert every candidate while backtracking.
+	parse func(s string) value
+	// err is the failure of parse like the number wider than 64 bits.
+	// It's reported when the value is stored into other than the variable
+	// which decodes itself like *big.Int.
+	err error
 }
 
+// deferred returns the value which is converted by parse after the match
+func deferred(parse func(s string) value) func(s string) value {
+	return func(s string) value {
+		return value{value: s, parse: parse}
+	}
+}
+
+
 type result struct {
 	err    error
 	values []value
+	// names are the names of values, unnamed value is ""
+	names []string
+	// start and end are the byte offsets of the matched text in the input
+	start, end int
 }
 
-func assignString(dest interface{}, src value) error {
-	switch d := dest.(type) {
-	case *string:
-		*d = src.value.(string)
+// typeName returns the name of the pointer to d used in the error, like *string
+func typeName(d reflect.Value) string {
+	return reflect.PtrTo(d.Type()).String()
+}
+
+func assignString(d reflect.Value, src value) error {
+	switch {
+	case d.Kind() == reflect.String:
+		d.SetString(src.value.(string))
 		return nil
-	case *[]byte:
-		*d = []byte(src.value.(string))
+	case d.Kind() == reflect.Slice && d.Type().Elem().Kind() == reflect.Uint8:
+		d.SetBytes([]byte(src.value.(string)))
 		return nil
 	default:
-		return fmt.Errorf("type mismatch: expected *string,*[]byte, actual %T", d)
+		return fmt.Errorf("type mismatch: expected *string,*[]byte, actual %s", typeName(d))
+	}
+}
+
+// signedValue returns the integer in src when it's in range of [min,max],
+// name is the type name used in the error.
+func signedValue(src value, name string, min, max int64) (int64, error) {
+	var n int64
+	switch v := src.value.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("overflow: %d is greater than Max%s(%d)", v, name, max)
+		}
+		n = int64(v)
+	}
+	if n > max {
+		return 0, fmt.Errorf("overflow: %d is greater than Max%s(%d)", n, name, max)
+	}
+	if n < min {
+		return 0, fmt.Errorf("underflow: %d is less than Min%s(%d)", n, name, min)
 	}
+	return n, nil
 }
 
-func assignInt(dest interface{}, src value) error {
-	switch d := dest.(type) {
-	case *int:
-		*d = src.value.(int)
+// unsignedValue returns the integer in src when it's in range of [0,max],
+// name is the type name used in the error.
+func unsignedValue(src value, name string, max uint64) (uint64, error) {
+	var u uint64
+	switch v := src.value.(type) {
+	case int:
+		if v < 0 {
+			return 0, fmt.Errorf("underflow: %d is negative, but %s is unsigned", v, name)
+		}
+		u = uint64(v)
+	case int64:
+		if v < 0 {
+			return 0, fmt.Errorf("underflow: %d is negative, but %s is unsigned", v, name)
+		}
+		u = uint64(v)
+	case uint64:
+		u = v
+	}
+	if u > max {
+		return 0, fmt.Errorf("overflow: %d is greater than Max%s(%d)", u, name, max)
+	}
+	return u, nil
+}
+
+func assignInt(d reflect.Value, src value) error {
+	// name is like Int8 or Uintptr
+	name := d.Kind().String()
+	name = strings.ToUpper(name[:1]) + name[1:]
+
+	switch d.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := uint(d.Type().Bits())
+		n, err := signedValue(src, name, -1<<(bits-1), 1<<(bits-1)-1)
+		if err != nil {
+			return err
+		}
+		d.SetInt(n)
 		return nil
-	case *int8:
-		if src.value.(int) > math.MaxInt8 {
-			return fmt.Errorf("overflow: %d is greater than MaxInt8(%d)",
-				src.value.(int), math.MaxInt8)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := uint(d.Type().Bits())
+		u, err := unsignedValue(src, name, math.MaxUint64>>(64-bits))
+		if err != nil {
+			return err
 		}
-		*d = int8(src.value.(int))
+		d.SetUint(u)
 		return nil
-	case *int32:
-		if src.value.(int) > math.MaxInt32 {
-			return fmt.Errorf("overflow: %d is greater than MaxInt32(%d)",
-				src.value.(int), math.MaxInt32)
+	case reflect.Float32, reflect.Float64:
+		// e.g. 2 written by %v for float64(2)
+		var f float64
+		switch n := src.value.(type) {
+		case int:
+			f = float64(n)
+		case int64:
+			f = float64(n)
+		case uint64:
+			f = float64(n)
+		}
+		return assignFloat(d, value{kind: reflect.Float64, value: f})
+	default:
+		return fmt.Errorf("type mismatch: expected *int{,8,16,32,64},*uint{,8,16,32,64,ptr}, actual %s", typeName(d))
+	}
+}
+
+// assignRune assigns the character read by %c, %U or %q
+func assignRune(d reflect.Value, src value) error {
+	r := src.value.(rune)
+	switch d.Kind() {
+	case reflect.Int32, reflect.Int:
+		d.SetInt(int64(r))
+		return nil
+	case reflect.Uint8:
+		if r > math.MaxUint8 {
+			return fmt.Errorf("overflow: %q is greater than MaxUint8(%d)",
+				r, math.MaxUint8)
 		}
-		*d = int32(src.value.(int))
+		d.SetUint(uint64(r))
 		return nil
-	case *int64:
-		// Note: if value is over MaxInt64, strconv.ParseInt failed
-		*d = int64(src.value.(int))
+	case reflect.String:
+		d.SetString(string(r))
 		return nil
 	default:
-		return fmt.Errorf("type mismatch: expected *int{8,32,64}, actual %T", d)
+		return fmt.Errorf("type mismatch: expected *rune,*byte,*int,*string, actual %s", typeName(d))
 	}
 }
 
-func assignStruct(dest interface{}, src value) error {
-	rt := reflect.ValueOf(dest)
-	for i, val := range src.value.([]interface{}) {
-		f := reflect.Indirect(rt).Field(i)
-		if !f.CanSet() {
-			return fmt.Errorf("target struct contains not exposed member")
-		}
-		switch v := val.(type) {
-		case string:
-			if f.Type() != reflect.TypeOf(v) {
-				return fmt.Errorf(
-					"invalid type expected: string, actual:%s",
-					f.Type().String())
-			}
-			f.SetString(v)
-		case int64:
-			if f.Kind() != reflect.Int &&
-				f.Kind() != reflect.Int32 &&
-				f.Kind() != reflect.Int64 {
-				return fmt.Errorf(
-					"invalid type expected: int, actual:%s",
-					f.Type().String())
-			}
-			f.SetInt(v)
-		case float64:
-			if f.Kind() != reflect.Float32 &&
-				f.Kind() != reflect.Float64 {
-				return fmt.Errorf(
-					"invalid type expected: float, actual:%s",
-					f.Type().String())
-			}
-			f.SetFloat(v)
-		case bool:
-			if f.Type() != reflect.TypeOf(v) {
-				return fmt.Errorf(
-					"invalid type expected: bool, actual:%s",
-					f.Type().String())
-			}
-			f.SetBool(v)
+// assignHex assigns the hex-encoded bytes read by %x
+func assignHex(d reflect.Value, src value) error {
+	// "% x" writes each byte separated by spaces, "% #x" with the prefix
+	var sb strings.Builder
+	for _, f := range strings.Fields(src.raw) {
+		if len(f) > 2 && (f[:2] == "0x" || f[:2] == "0X") {
+			f = f[2:]
 		}
+		sb.WriteString(f)
 	}
-	return nil
+	s := sb.String()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return errors.Wrapf(err, "DecodeString(%s) failed", s)
+	}
+	return assignString(d, value{kind: reflect.String, value: string(b)})
 }
 
-func assignFloat(dest interface{}, src value) error {
-	switch d := dest.(type) {
-	case *float64:
-		*d = src.value.(float64)
+func assignFloat(d reflect.Value, src value) error {
+	f := src.value.(float64)
+	switch d.Kind() {
+	case reflect.Float64:
+		d.SetFloat(f)
 		return nil
-	case *float32:
-		*d = float32(src.value.(float64))
+	case reflect.Float32:
+		if !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+			return fmt.Errorf("overflow: %g is out of range of float32", f)
+		}
+		d.SetFloat(f)
 		return nil
 	default:
-		return nil
+		return fmt.Errorf("type mismatch: expected *float{32,64}, actual %s", typeName(d))
 	}
 }
 
+// text returns the text of src handed to decoders.
+// It's the string for string values (e.g. unquoted for %q), otherwise
+// the text in the input without padding.
+func (v value) text() string {
+	if s, ok := v.value.(string); ok {
+		return s
+	}
+	return strings.TrimSpace(v.raw)
+}
+
+// numeric reports whether v is read by the verb of number like %d or %x
+func (v value) numeric() bool {
+	switch v.verb {
+	case 'd', 'b', 'o', 'O', 'x', 'X', 'e', 'E', 'f', 'F', 'g', 'G':
+		return true
+	}
+	return false
+}
+
+// decodes reports whether the variable of type t, or the variable it points
+// to, decodes itself
+func decodes(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	p := reflect.PtrTo(t)
+	return p.Implements(textUnmarshalerType) || p.Implements(scannerType) ||
+		p.Implements(sqlScannerType)
+}
+
+// driverValue returns the value of src handed to sql.Scanner,
+// integers are int64 like database/sql/driver.Value.
+func (v value) driverValue() interface{} {
+	switch n := v.value.(type) {
+	case int:
+		return int64(n)
+	case rune:
+		return int64(n)
+	case []interface{}:
+		return v.text()
+	}
+	return v.value
+}
+
+// assignDecoder stores src using the method of d when d decodes itself,
+// it returns false when d implements none of
+// encoding.TextUnmarshaler, fmt.Scanner and sql.Scanner.
+func assignDecoder(d reflect.Value, src value) (bool, error) {
+	if !d.CanAddr() {
+		return false, nil
+	}
+	if sc, ok := d.Addr().Interface().(fmt.Scanner); ok && src.numeric() {
+		// The number is scanned with the verb to keep its base, e.g. %x into *big.Int
+		sp := &spec{verb: src.verb, sharp: src.sharp}
+		text := trimPrefix(src.text(), sp.prefix())
+		verb := "%" + string(src.verb)
+		if src.verb == 'O' {
+			verb = "%o"
+		}
+		if _, err := fmt.Sscanf(text, verb, sc); err != nil {
+			return true, errors.Wrapf(err, "Scan(%s) failed", text)
+		}
+		return true, nil
+	}
+	switch dec := d.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		if err := dec.UnmarshalText([]byte(src.text())); err != nil {
+			return true, errors.Wrapf(err, "UnmarshalText(%s) failed", src.text())
+		}
+		return true, nil
+	case fmt.Scanner:
+		verb := "%v"
+		if src.verb != 0 {
+			verb = "%" + string(src.verb)
+		}
+		if _, err := fmt.Sscanf(src.text(), verb, dec); err != nil {
+			return true, errors.Wrapf(err, "Scan(%s) failed", src.text())
+		}
+		return true, nil
+	case sql.Scanner:
+		if err := dec.Scan(src.driverValue()); err != nil {
+			return true, errors.Wrapf(err, "Scan(%v) failed", src.driverValue())
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// assign stores src into the variable dest points to.
+// The variable can be any type whose underlying kind fits src, like
+// `type UserID int64`. The pointer to pointer is allocated as needed and
+// *interface{} receives the Go value of src as it is.
+// The variable which implements encoding.TextUnmarshaler, fmt.Scanner or
+// sql.Scanner decodes the text of src by itself, and fmt.Scanner scans
+// the number with the verb which read it like %x.
 func assign(dest interface{}, src value) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("invalid destination: expected non-nil pointer, actual %T", dest)
+	}
+	return assignValue(rv.Elem(), src)
+}
+
+// assignValue stores src into d
+func assignValue(d reflect.Value, src value) error {
+	if src.err != nil && d.Kind() != reflect.Interface && !decodes(d.Type()) {
+		// e.g. the number wider than 64 bits into int
+		return src.err
+	}
+	if src.verb == 'v' && src.sharp {
+		return assignGo(d, src)
+	}
+	if v := reflect.ValueOf(src.value); v.Type() == d.Type() {
+		d.Set(v)
+		return nil
+	} else if v.Kind() == reflect.Ptr && v.Type().Elem() == d.Type() && !v.IsNil() {
+		// e.g. *url.URL into url.URL
+		d.Set(v.Elem())
+		return nil
+	}
+
+	if src.verb == 'v' && src.text() == "<nil>" {
+		switch d.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			// nil written by %v
+			d.Set(reflect.Zero(d.Type()))
+			return nil
+		}
+	}
+
+	if d.Kind() == reflect.Ptr {
+		if !d.IsNil() {
+			return assignValue(d.Elem(), src)
+		}
+		// d is set only when the assignment succeeds
+		p := reflect.New(d.Type().Elem())
+		if err := assignValue(p.Elem(), src); err != nil {
+			return err
+		}
+		d.Set(p)
+		return nil
+	}
+
+	if d.Kind() == reflect.Interface {
+		v := reflect.ValueOf(src.value)
+		if !v.Type().AssignableTo(d.Type()) {
+			return fmt.Errorf("type mismatch: %s doesn't implement %s",
+				v.Type().String(), d.Type().String())
+		}
+		d.Set(v)
+		return nil
+	}
+
+	if ok, err := assignDecoder(d, src); ok {
+		return err
+	}
+
+	switch d.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		// e.g. "[1 2 3]" or "map[a:1]" written by %v
+		if src.verb == 'v' && composite(src.text()) {
+			return assignComposite(d, src)
+		}
+	}
+
+	if src.verb == 'x' || src.verb == 'X' {
+		if d.Kind() == reflect.String ||
+			(d.Kind() == reflect.Slice && d.Type().Elem().Kind() == reflect.Uint8) {
+			return assignHex(d, src)
+		}
+	}
+
 	switch src.kind {
 	case reflect.String:
-		return assignString(dest, src)
-	case reflect.Int:
-		return assignInt(dest, src)
+		return assignString(d, src)
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		return assignInt(d, src)
 	case reflect.Bool:
-		switch d := dest.(type) {
-		case *bool:
-			*d = src.value.(bool)
+		if d.Kind() == reflect.Bool {
+			d.SetBool(src.value.(bool))
 			return nil
 		}
+	case reflect.Int32:
+		return assignRune(d, src)
 	case reflect.Float64:
-		return assignFloat(dest, src)
+		return assignFloat(d, src)
 	case reflect.Struct:
-		return assignStruct(dest, src)
+		return assignStruct(d, src)
+	case reflect.Interface:
+		// The value like time.Time is stored into string as it's written
+		if d.Kind() == reflect.String {
+			d.SetString(src.text())
+			return nil
+		}
 	}
 	return fmt.Errorf("unsupported type %s into type %s",
-		src.kind.String(), reflect.TypeOf(dest).Kind().String())
+		src.kind.String(), d.Kind().String())
 }
 
 func (r result) Insert(dest ...interface{}) error {
@@ -243,7 +554,7 @@ func (r result) Insert(dest ...interface{}) error {
 	for i, sv := range r.values {
 		err := assign(dest[i], sv)
 		if err != nil {
-			return fmt.Errorf(`assign(src{kind:%s,%v} => dest[%d]) failed err:%s`,
+			return fmt.Errorf(`assign(src{kind:%s,%v} => dest[%d]) failed err:%w`,
 				sv.kind.String(), sv.value, i, err)
 		}
 	}
@@ -266,7 +577,7 @@ func (r result) InsertOnly(index uint, dest interface{}) error {
 		if i == int(index) {
 			err := assign(dest, sv)
 			if err != nil {
-				return fmt.Errorf(`assign(src{kind:%s,%v} => dest[%d]) failed err:%s`,
+				return fmt.Errorf(`assign(src{kind:%s,%v} => dest[%d]) failed err:%w`,
 					sv.kind.String(), sv.value, i, err)
 			}
 		}
@@ -274,219 +585,123 @@ func (r result) InsertOnly(index uint, dest interface{}) error {
 	return nil
 }
 
+func (r result) Get(name string, dest interface{}) error {
+	// r.err is happened by parser
+	if r.err != nil {
+		return r.err
+	}
+
+	for i, n := range r.names {
+		if n == name {
+			sv := r.values[i]
+			err := assign(dest, sv)
+			if err != nil {
+				return fmt.Errorf(`assign(src{kind:%s,%v} => dest[%s]) failed err:%w`,
+					sv.kind.String(), sv.value, name, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid name:%s, format has no verb named it", name)
+}
+
+func (r result) Names() []string {
+	var names []string
+	for _, n := range r.names {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (r result) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	for i, n := range r.names {
+		if n != "" && i < len(r.values) {
+			m[n] = r.values[i].value
+		}
+	}
+	return m
+}
+
+func (r result) Bounds() (start, end int) {
+	return r.start, r.end
+}
+
+func (r result) Len() int {
+	return len(r.values)
+}
+
+// Kind returns the kind of the value.
+// The kind of %v struct is reflect.Struct even though Value is []interface{},
+// and the kind of the value like time.Time is the kind of its type.
+// It panics if i is out of range.
+func (r result) Kind(i int) reflect.Kind {
+	v := r.values[i]
+	if v.kind == reflect.Interface && v.value != nil {
+		return reflect.TypeOf(v.value).Kind()
+	}
+	return v.kind
+}
+
+// Value returns the value. It panics if i is out of range.
+func (r result) Value(i int) interface{} {
+	return r.values[i].value
+}
+
+// Raw returns the consumed text. It panics if i is out of range.
+func (r result) Raw(i int) string {
+	return r.values[i].raw
+}
+
+// Span returns the byte offsets. It panics if i is out of range.
+func (r result) Span(i int) (start, end int) {
+	v := r.values[i]
+	return v.start, v.start + len(v.raw)
+}
+
 // Parse parse str uses format
 func Parse(format, str string) Result {
-	var res result
-	end := len(format)
-	strOffset := 0
-
-	for i := 0; i < end; {
-		if format[i] != '%' && format[i] != str[strOffset+i] {
-			return result{
-				err: fmt.Errorf("invalid string (%s) with (%s). expect %c but it is %c",
-					str, format, format[i], str[strOffset+i]),
-			}
+	f, err := Compile(format)
+	if err != nil {
+		return result{err: err}
+	}
+	return f.Parse(str)
+}
+
+// parseStruct returns attributes of struct formatted by %v
+// ("{Hello 123 true}") => ["Hello", 123, true]
+// The nested struct is the nested attributes, and "&{...}" is same as "{...}".
+func parseStruct(s string) []interface{} {
+	s = strings.TrimPrefix(s, "&")
+	slice := splitFields(s[1 : len(s)-1])
+	attrs := make([]interface{}, 0, len(slice))
+	for _, attr := range slice {
+		if structText(attr) {
+			attrs = append(attrs, parseStruct(attr))
+			continue
 		}
 
-		if format[i] == '%' {
-			for ; i < end; i++ {
-				c := format[i]
-				if i+2 < end && format[i+1] == '%' {
-					return result{
-						err: fmt.Errorf(
-							"invalid format(\"%s\"). too ambiguous to invese format",
-							format),
-					}
-				}
-				switch c {
-				case 's':
-					// first arguments except format
-					s, err := parseString(format[i+1:], str[strOffset+i-1:])
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseString(%s,%s) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					strOffset += len(s) - 2
-					res.values = append(res.values, value{
-						reflect.String, s})
-					i += 2
-					goto formatLoop
-				case 'd':
-					// first arguments except format
-					n, err := parseInteger(format[i+1:], str[strOffset+i-1:], 10)
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseInteger(%%%s,\"%s\",10) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					strOffset += len(strconv.Itoa(n)) - 2
-					res.values = append(res.values, value{
-						reflect.Int, n})
-					i += 2
-					goto formatLoop
-				case 'b':
-					// first arguments except format
-					n, err := parseInteger(format[i+1:], str[strOffset+i-1:], 2)
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseInteger(%%%s,\"%s\",2) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					/*
-						Note: this function never returns error.
-						 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-						 So str must contain format text before '%'
-					*/
-					offset, _ := parseString(format[i+1:], str[strOffset+i-1:])
-
-					strOffset += len(offset) - 2
-					res.values = append(res.values, value{
-						reflect.Int, n})
-					i += 2
-					goto formatLoop
-				case 'o':
-					// first arguments except format
-					n, err := parseInteger(format[i+1:], str[strOffset+i-1:], 8)
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseInteger(%s,%s,8) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					/*
-						Note: this function never returns error.
-						 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-						 So str must contain format text before '%'
-					*/
-					offset, _ := parseString(format[i+1:], str[strOffset+i-1:])
-
-					strOffset += len(offset) - 2
-					res.values = append(res.values, value{
-						reflect.Int, n})
-					i += 2
-					goto formatLoop
-				case 't':
-					// first arguments except format
-					b, err := parseBool(format[i+1:], str[strOffset+i-1:])
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseBool(%s,%s) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					strOffset += len(strconv.FormatBool(b)) - 2
-					res.values = append(res.values, value{
-						reflect.Bool, b})
-					i += 2
-					goto formatLoop
-				case 'f':
-					// first arguments except format
-					f, err := parseFloat(format[i+1:], str[strOffset+i-1:])
-					if err != nil {
-						return result{
-							err: errors.Wrapf(err, "parseFloat(%s,%s) failed",
-								format[i:], str[strOffset+i-1:]),
-						}
-					}
-					/*
-						Note: this function never returns error.
-						 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-						 So str must contain format text before '%'
-					*/
-					offset, _ := parseString(format[i+1:], str[strOffset+i-1:])
-
-					strOffset += len(offset) - 2
-					res.values = append(res.values, value{
-						reflect.Float64, f})
-					i += 2
-					goto formatLoop
-				case 'v':
-					{
-						n, err := parseInteger(format[i+1:], str[strOffset+i-1:], 10)
-						if err == nil {
-							strOffset += len(strconv.Itoa(n)) - 2
-							res.values = append(res.values, value{
-								reflect.Int, n})
-							i += 2
-							goto formatLoop
-						}
-					}
-					{
-						b, err := parseBool(format[i+1:], str[strOffset+i-1:])
-						if err == nil {
-							strOffset += len(strconv.FormatBool(b)) - 2
-							res.values = append(res.values, value{
-								reflect.Bool, b})
-							i += 2
-							goto formatLoop
-						}
-					}
-					{
-						f, err := parseFloat(format[i+1:], str[strOffset+i-1:])
-						if err == nil {
-							offset, _ := parseString(format[i+1:], str[strOffset+i-1:])
-
-							strOffset += len(offset) - 2
-							res.values = append(res.values, value{
-								reflect.Float64, f})
-							i += 2
-							goto formatLoop
-						}
-					}
-					/*
-						Note: this function never returns error.
-							 Because in Parse, check `format[i] != '%' && format[i] != str[strOffset+i]`.
-							 So str must contain format text before '%'
-					*/
-					s, _ := parseString(format[i+1:], str[strOffset+i-1:])
-
-					if s[0] == '{' && s[len(s)-1] == '}' {
-						slice := strings.Split(s[1:len(s)-1], " ")
-						attrs := make([]interface{}, 0, len(slice))
-						for _, attr := range slice {
-
-							attrI, err := strconv.ParseInt(attr, 10, 0)
-							if err == nil {
-								attrs = append(attrs, attrI)
-								continue
-							}
-
-							attrB, err := strconv.ParseBool(attr)
-							if err == nil {
-								attrs = append(attrs, attrB)
-								continue
-							}
-
-							attrF, err := strconv.ParseFloat(attr, 0)
-							if err == nil {
-								attrs = append(attrs, attrF)
-								continue
-							}
-
-							attrs = append(attrs, attr)
-						}
-
-						strOffset += len(s) - 2
-						res.values = append(res.values, value{
-							reflect.Struct, attrs})
-						i += 2
-						goto formatLoop
-					} else {
-						strOffset += len(s) - 2
-						res.values = append(res.values, value{
-							reflect.String, s})
-						i += 2
-						goto formatLoop
-					}
-				}
-			}
+		attrI, err := strconv.ParseInt(attr, 10, 0)
+		if err == nil {
+			attrs = append(attrs, attrI)
+			continue
 		}
-		i++
-	formatLoop:
+
+		attrB, err := strconv.ParseBool(attr)
+		if err == nil {
+			attrs = append(attrs, attrB)
+			continue
+		}
+
+		attrF, err := strconv.ParseFloat(attr, 0)
+		if err == nil {
+			attrs = append(attrs, attrF)
+			continue
+		}
+
+		attrs = append(attrs, attr)
 	}
-	return res
+	return attrs
 }
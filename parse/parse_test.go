@@ -3,11 +3,17 @@
 package goparse_test
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	goparse "github.com/MizukiSonoko/goparse/parse"
 	"github.com/stretchr/testify/assert"
@@ -118,7 +124,7 @@ func TestParse(t *testing.T) {
 	})
 
 	t.Run("format contains an unsupported type", func(t *testing.T) {
-		format := "Hello I want a coffee %g gram"
+		format := "Hello I want a coffee %p gram"
 		str := "Hello I want a coffee 123.456 gram"
 		var res float32
 		err := goparse.Parse(format, str).Insert(&res)
@@ -460,6 +466,104 @@ func TestParse_integer(t *testing.T) {
 			assert.Errorf(t, err, "Parse(%s,%s) failed")
 		})
 
+		t.Run("the argument is int16", func(t *testing.T) {
+			format := "-%d-"
+			for _, expected := range []int16{math.MinInt16, -1, 0, math.MaxInt16} {
+				var res int16
+				err := goparse.Parse(format, fmt.Sprintf(format, expected)).Insert(&res)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, res)
+			}
+		})
+
+		t.Run("str happens underflow", func(t *testing.T) {
+			var res8 int8
+			err := goparse.Parse("%d", fmt.Sprint(math.MinInt8-1)).Insert(&res8)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "underflow")
+
+			var res16 int16
+			err = goparse.Parse("%d", fmt.Sprint(math.MinInt16-1)).Insert(&res16)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "underflow")
+
+			var res32 int32
+			err = goparse.Parse("%d", fmt.Sprint(math.MinInt32-1)).Insert(&res32)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "underflow")
+		})
+
+		t.Run("the argument is int64 out of range of int", func(t *testing.T) {
+			for _, expected := range []int64{math.MinInt64, math.MaxInt64} {
+				var res int64
+				err := goparse.Parse("%d", fmt.Sprint(expected)).Insert(&res)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, res)
+			}
+		})
+
+		t.Run("the argument is unsigned", func(t *testing.T) {
+			var u uint
+			var u8 uint8
+			var u16 uint16
+			var u32 uint32
+			var u64 uint64
+			var ptr uintptr
+			format := "%d %d %d %d %d %x"
+			str := fmt.Sprintf(format, uint(7), uint8(math.MaxUint8), uint16(math.MaxUint16),
+				uint32(math.MaxUint32), uint64(math.MaxUint64), uintptr(0xc000010000))
+			err := goparse.Parse(format, str).Insert(&u, &u8, &u16, &u32, &u64, &ptr)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(7), u)
+			assert.Equal(t, uint8(math.MaxUint8), u8)
+			assert.Equal(t, uint16(math.MaxUint16), u16)
+			assert.Equal(t, uint32(math.MaxUint32), u32)
+			assert.Equal(t, uint64(math.MaxUint64), u64)
+			assert.Equal(t, uintptr(0xc000010000), ptr)
+		})
+
+		t.Run("the argument is unsigned, and str happens overflow", func(t *testing.T) {
+			var u8 uint8
+			err := goparse.Parse("%d", fmt.Sprint(math.MaxUint8+1)).Insert(&u8)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "overflow")
+
+			var u32 uint32
+			err = goparse.Parse("%d", fmt.Sprint(math.MaxUint32+1)).Insert(&u32)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "overflow")
+
+			var u64 uint64
+			err = goparse.Parse("%d", "18446744073709551616").Insert(&u64)
+			assert.Error(t, err)
+		})
+
+		t.Run("negative value into unsigned", func(t *testing.T) {
+			var u uint
+			err := goparse.Parse("%d", "-1").Insert(&u)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "negative")
+
+			var u64 uint64
+			err = goparse.Parse("%d", fmt.Sprint(int64(math.MinInt64))).Insert(&u64)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "negative")
+		})
+
+		t.Run("uint64 into signed", func(t *testing.T) {
+			var res int64
+			err := goparse.Parse("%d", fmt.Sprint(uint64(math.MaxUint64))).Insert(&res)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "overflow")
+		})
+
+		t.Run("destination is not changed on failure", func(t *testing.T) {
+			res := int8(42)
+			err := goparse.Parse("%d", "1000").Insert(&res)
+			assert.Error(t, err)
+			assert.Equal(t, int8(42), res)
+		})
+
 	})
 
 	t.Run("case single", func(t *testing.T) {
@@ -594,6 +698,16 @@ func TestParse_integer_base8(t *testing.T) {
 		assert.Equal(t, expected2, res2)
 	})
 
+	t.Run("with 0o prefix", func(t *testing.T) {
+		format := "mode=%O"
+		for _, expected := range []int{0, 15, -493} {
+			var res int
+			err := goparse.Parse(format, fmt.Sprintf(format, expected)).Insert(&res)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, res)
+		}
+	})
+
 	t.Run("case multiple 3", func(t *testing.T) {
 		format := "%o %o %o"
 		expected1 := 123
@@ -737,10 +851,13 @@ func TestParse_boolean(t *testing.T) {
 			err := goparse.Parse(format, str).Insert(&res)
 			assert.Errorf(t, err, "Parse(%s,%s) not failed want fail")
 
-			if !strings.Contains(err.Error(), "parseBool") {
-				t.Errorf("Parse error should contain %s, but it's %s",
-					"parseBool", err.Error())
-
+			var perr *goparse.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse error should be ParseError, but it's %T", err)
+			}
+			if perr.Verb != "%t" {
+				t.Errorf("Parse error should be happened at %s, but it's %s",
+					"%t", perr.Verb)
 			}
 		})
 
@@ -1017,6 +1134,309 @@ func TestParse_value_primitive(t *testing.T) {
 
 }
 
+func TestParse_named(t *testing.T) {
+
+	t.Run("Get by name", func(t *testing.T) {
+		format := "user=%{user}s id=%{id}d"
+		str := "user=iori id=17"
+		r := goparse.Parse(format, str)
+
+		var user string
+		var id int
+		assert.NoError(t, r.Get("user", &user))
+		assert.NoError(t, r.Get("id", &id))
+		assert.Equal(t, "iori", user)
+		assert.Equal(t, 17, id)
+		assert.Equal(t, []string{"user", "id"}, r.Names())
+		assert.Equal(t, map[string]interface{}{"user": "iori", "id": 17}, r.Map())
+	})
+
+	t.Run("named and unnamed verbs are mixed", func(t *testing.T) {
+		format := "%s: user=%{user}s ok=%t"
+		str := "login: user=sonoko ok=true"
+		r := goparse.Parse(format, str)
+
+		var event, user string
+		var ok bool
+		assert.NoError(t, r.Insert(&event, &user, &ok))
+		assert.Equal(t, "login", event)
+		assert.Equal(t, "sonoko", user)
+		assert.Equal(t, true, ok)
+
+		user = ""
+		assert.NoError(t, r.InsertOnly(1, &user))
+		assert.Equal(t, "sonoko", user)
+		assert.Equal(t, []string{"user"}, r.Names())
+		assert.Equal(t, map[string]interface{}{"user": "sonoko"}, r.Map())
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		var user string
+		err := goparse.Parse("user=%{user}s", "user=iori").Get("name", &user)
+		assert.Error(t, err)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var user int
+		err := goparse.Parse("user=%{user}s", "user=iori").Get("user", &user)
+		assert.Error(t, err)
+	})
+
+	t.Run("parse failed", func(t *testing.T) {
+		var id int
+		r := goparse.Parse("id=%{id}d", "id=none")
+		assert.Error(t, r.Get("id", &id))
+		assert.Empty(t, r.Names())
+		assert.Empty(t, r.Map())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		for _, format := range []string{
+			"%{id}d %{id}d",
+			"%{}d",
+			"%{id",
+			"%{id}",
+		} {
+			_, err := goparse.Compile(format)
+			assert.Errorf(t, err, "Compile(%s) not failed want fail", format)
+		}
+	})
+}
+
+func TestParse_destination(t *testing.T) {
+
+	t.Run("named types", func(t *testing.T) {
+		type UserID int64
+		type Level string
+		type Ratio float32
+		type Enabled bool
+		type Port uint16
+		var id UserID
+		var level Level
+		var ratio Ratio
+		var enabled Enabled
+		var port Port
+		format := "id=%d level=%s ratio=%f enabled=%t port=%d"
+		str := "id=42 level=debug ratio=0.500000 enabled=true port=8080"
+		err := goparse.Parse(format, str).Insert(&id, &level, &ratio, &enabled, &port)
+		assert.NoError(t, err)
+		assert.Equal(t, UserID(42), id)
+		assert.Equal(t, Level("debug"), level)
+		assert.Equal(t, Ratio(0.5), ratio)
+		assert.Equal(t, Enabled(true), enabled)
+		assert.Equal(t, Port(8080), port)
+	})
+
+	t.Run("named type is checked the range", func(t *testing.T) {
+		type Port uint16
+		var port Port
+		err := goparse.Parse("port=%d", "port=65536").Insert(&port)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "overflow")
+	})
+
+	t.Run("named slice of bytes", func(t *testing.T) {
+		type Raw []byte
+		var raw Raw
+		err := goparse.Parse("raw=%s", "raw=abc").Insert(&raw)
+		assert.NoError(t, err)
+		assert.Equal(t, Raw("abc"), raw)
+	})
+
+	t.Run("named struct field", func(t *testing.T) {
+		type Name string
+		type sample struct {
+			Name  Name
+			Count int16
+		}
+		var res sample
+		err := goparse.Parse("%v", "{iori 17}").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, sample{Name: "iori", Count: 17}, res)
+	})
+
+	t.Run("pointer to pointer", func(t *testing.T) {
+		var name *string
+		var count **int
+		err := goparse.Parse("%s=%d", "iori=17").Insert(&name, &count)
+		assert.NoError(t, err)
+		if assert.NotNil(t, name) && assert.NotNil(t, count) && assert.NotNil(t, *count) {
+			assert.Equal(t, "iori", *name)
+			assert.Equal(t, 17, **count)
+		}
+	})
+
+	t.Run("existing pointer is reused", func(t *testing.T) {
+		n := 1
+		p := &n
+		err := goparse.Parse("%d", "17").Insert(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 17, n)
+	})
+
+	t.Run("pointer is not allocated on failure", func(t *testing.T) {
+		var p *int8
+		err := goparse.Parse("%d", "1000").Insert(&p)
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var s, d, b, f, c interface{}
+		format := "%s %d %t %f %c"
+		str := "iori 17 true 1.500000 x"
+		err := goparse.Parse(format, str).Insert(&s, &d, &b, &f, &c)
+		assert.NoError(t, err)
+		assert.Equal(t, "iori", s)
+		assert.Equal(t, 17, d)
+		assert.Equal(t, true, b)
+		assert.Equal(t, 1.5, f)
+		assert.Equal(t, 'x', c)
+	})
+
+	t.Run("interface which is not implemented", func(t *testing.T) {
+		var s fmt.Stringer
+		err := goparse.Parse("%s", "iori").Insert(&s)
+		assert.Error(t, err)
+	})
+
+	t.Run("not pointer", func(t *testing.T) {
+		var s string
+		err := goparse.Parse("%s", "iori").Insert(s)
+		assert.Error(t, err)
+	})
+}
+
+// version implements fmt.Scanner
+type version struct {
+	major, minor int
+}
+
+func (v *version) Scan(state fmt.ScanState, verb rune) error {
+	_, err := fmt.Fscanf(state, "v%d.%d", &v.major, &v.minor)
+	return err
+}
+
+// celsius implements sql.Scanner
+type celsius float64
+
+func (c *celsius) Scan(src interface{}) error {
+	f, ok := src.(float64)
+	if !ok {
+		return fmt.Errorf("celsius: unsupported %T", src)
+	}
+	*c = celsius(f)
+	return nil
+}
+
+func TestParse_decoder(t *testing.T) {
+
+	t.Run("encoding.TextUnmarshaler", func(t *testing.T) {
+		var addr netip.Addr
+		var n big.Int
+		format := "from %s size=%s"
+		str := "from 192.0.2.1 size=123456789012345678901234567890"
+		err := goparse.Parse(format, str).Insert(&addr, &n)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+		assert.Equal(t, "123456789012345678901234567890", n.String())
+	})
+
+	t.Run("pointer to decoder is allocated", func(t *testing.T) {
+		var n *big.Int
+		err := goparse.Parse("%d", "42").Insert(&n)
+		assert.NoError(t, err)
+		if assert.NotNil(t, n) {
+			assert.Equal(t, int64(42), n.Int64())
+		}
+	})
+
+	t.Run("number verbs into big.Int", func(t *testing.T) {
+		for _, c := range []struct {
+			format   string
+			str      string
+			expected string
+		}{
+			{"%x", "ff", "255"},
+			{"%X", "FF", "255"},
+			{"%#x", "0xff", "255"},
+			{"%b", "101", "5"},
+			{"%o", "17", "15"},
+			{"%O", "0o17", "15"},
+			{"%d", "-123456789012345678901234567890", "-123456789012345678901234567890"},
+			{"%x", "123456789abcdef0123456789abcdef", "1512366075204170929049582354406559215"},
+			{"[%d]", "[99999999999999999999]", "99999999999999999999"},
+		} {
+			var n big.Int
+			err := goparse.Parse(c.format, c.str).Insert(&n)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", c.format, c.str)
+			assert.Equalf(t, c.expected, n.String(), "Parse(%s,%s)", c.format, c.str)
+		}
+
+		var p *big.Int
+		err := goparse.Parse("%d", "99999999999999999999").Insert(&p)
+		assert.NoError(t, err)
+		if assert.NotNil(t, p) {
+			assert.Equal(t, "99999999999999999999", p.String())
+		}
+
+		var i int64
+		err = goparse.Parse("%d", "99999999999999999999").Insert(&i)
+		assert.Error(t, err)
+	})
+
+	t.Run("the text is unquoted", func(t *testing.T) {
+		var addr netip.Addr
+		err := goparse.Parse("addr=%q", `addr="::1"`).Insert(&addr)
+		assert.NoError(t, err)
+		assert.Equal(t, netip.IPv6Loopback(), addr)
+	})
+
+	t.Run("fmt.Scanner", func(t *testing.T) {
+		var v version
+		err := goparse.Parse("go%s", "gov1.12").Insert(&v)
+		assert.NoError(t, err)
+		assert.Equal(t, version{major: 1, minor: 12}, v)
+	})
+
+	t.Run("sql.Scanner", func(t *testing.T) {
+		var c celsius
+		var count sql.NullInt64
+		var name sql.NullString
+		format := "%f C %d %s"
+		err := goparse.Parse(format, "36.5 C 3 iori").Insert(&c, &count, &name)
+		assert.NoError(t, err)
+		assert.Equal(t, celsius(36.5), c)
+		assert.Equal(t, sql.NullInt64{Int64: 3, Valid: true}, count)
+		assert.Equal(t, sql.NullString{String: "iori", Valid: true}, name)
+	})
+
+	t.Run("decoder failed", func(t *testing.T) {
+		var addr netip.Addr
+		err := goparse.Parse("from %s", "from localhost").Insert(&addr)
+		assert.Error(t, err)
+
+		var v version
+		err = goparse.Parse("go%s", "gotip").Insert(&v)
+		assert.Error(t, err)
+
+		var c celsius
+		err = goparse.Parse("%s C", "hot C").Insert(&c)
+		assert.Error(t, err)
+	})
+
+	t.Run("Unmarshal into decoder fields", func(t *testing.T) {
+		type access struct {
+			Addr netip.Addr `goparse:"addr"`
+			Path string     `goparse:"path"`
+		}
+		var res access
+		err := goparse.Unmarshal("%{addr}s GET %{path}s", "::1 GET /", &res)
+		assert.NoError(t, err)
+		assert.Equal(t, access{Addr: netip.IPv6Loopback(), Path: "/"}, res)
+	})
+}
+
 func TestInsertOnly_normal(t *testing.T) {
 
 	t.Run("working case", func(t *testing.T) {
@@ -1071,6 +1491,64 @@ func TestInsertOnly_normal(t *testing.T) {
 	})
 }
 
+func TestResult_introspection(t *testing.T) {
+
+	t.Run("values of verbs", func(t *testing.T) {
+		str := "GET /index.html 200 12.5ms true"
+		r := goparse.Parse("%s %s %d %D %t", str)
+		assert.Equal(t, 5, r.Len())
+
+		kinds := []reflect.Kind{reflect.String, reflect.String, reflect.Int, reflect.Int64, reflect.Bool}
+		values := []interface{}{"GET", "/index.html", 200, 12500 * time.Microsecond, true}
+		raws := []string{"GET", "/index.html", "200", "12.5ms", "true"}
+		for i := 0; i < r.Len(); i++ {
+			assert.Equal(t, kinds[i], r.Kind(i))
+			assert.Equal(t, values[i], r.Value(i))
+			assert.Equal(t, raws[i], r.Raw(i))
+			start, end := r.Span(i)
+			assert.Equal(t, raws[i], str[start:end])
+		}
+	})
+
+	t.Run("raw keeps the text as it is", func(t *testing.T) {
+		r := goparse.Parse("%x|%5d|%v", "ff|   42|{a 1}")
+		assert.Equal(t, 255, r.Value(0))
+		assert.Equal(t, "ff", r.Raw(0))
+		assert.Equal(t, "   42", r.Raw(1))
+		assert.Equal(t, reflect.Struct, r.Kind(2))
+		assert.Equal(t, "{a 1}", r.Raw(2))
+		start, end := r.Span(2)
+		assert.Equal(t, 9, start)
+		assert.Equal(t, 14, end)
+	})
+
+	t.Run("span is the offset in the whole input for Search", func(t *testing.T) {
+		str := "name=iori; id=12;"
+		r := goparse.Search("id=%d;", str)
+		start, end := r.Span(0)
+		assert.Equal(t, "12", str[start:end])
+		assert.Equal(t, 14, start)
+	})
+
+	t.Run("failed result has no value", func(t *testing.T) {
+		r := goparse.Parse("%d", "abc")
+		assert.Equal(t, 0, r.Len())
+		assert.Panics(t, func() { r.Raw(0) })
+	})
+}
+
+func ExampleResult_Span() {
+	str := "user=iori id=12"
+	r := goparse.Parse("user=%s id=%d", str)
+	for i := 0; i < r.Len(); i++ {
+		start, end := r.Span(i)
+		fmt.Printf("%s %q [%d:%d]\n", r.Kind(i), r.Raw(i), start, end)
+	}
+	// Output:
+	// string "iori" [5:9]
+	// int "12" [13:15]
+}
+
 func ExampleParse() {
 	var str string
 	_ = goparse.Parse("Hello %s", "Hello World").Insert(&str)
@@ -1129,6 +1607,20 @@ func ExampleParse_number() {
 	// 101
 }
 
+func ExampleParse_named() {
+	format := "%{method}s %{path}s %{status}d"
+	str := "GET /index.html 200"
+	r := goparse.Parse(format, str)
+
+	var path string
+	_ = r.Get("path", &path)
+	fmt.Println(path)
+	fmt.Println(r.Names())
+	// Output:
+	// /index.html
+	// [method path status]
+}
+
 func ExampleParse_boolean() {
 	format := "I can't tell whether it is %t or %t"
 	str := "I can't tell whether it is false or true"
@@ -1172,7 +1664,7 @@ func ExampleParse_failedInsertIntToString() {
 	err := goparse.Parse(format, str).Insert(&resInvalidType)
 	fmt.Println(err.Error())
 	// Output:
-	// assign(src{kind:int,1} => dest[0]) failed err:type mismatch: expected *int{8,32,64}, actual *string
+	// assign(src{kind:int,1} => dest[0]) failed err:type mismatch: expected *int{,8,16,32,64},*uint{,8,16,32,64,ptr}, actual *string
 }
 
 func ExampleParse_failedAmbigurousFormat() {
@@ -1194,5 +1686,96 @@ func ExampleParse_failed() {
 	err := goparse.Parse(format, str).Insert(&resInvalidType)
 	fmt.Println(err.Error())
 	// Output:
-	// parseInteger(%d,"One",10) failed: ParseInt("One",10) failed: strconv.ParseInt: parsing "One": invalid syntax
+	// goparse: invalid value "One" for %d at offset 21 (line 1, column 22): invalid syntax
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+
+	t.Run("working case", func(t *testing.T) {
+		for _, c := range []struct {
+			format string
+			values []interface{}
+		}{
+			{"%s=%d", []interface{}{"id", 17}},
+			{"%s %t %x", []interface{}{"iori", true, uint8(255)}},
+			{"%v|%v", []interface{}{[]string{"a", "b"}, map[string]int{"a": 1}}},
+			{"%+v", []interface{}{struct{ Name string }{"iori"}}},
+			{"%q,%c", []interface{}{"a,b", 'x'}},
+			{"%.1f %g", []interface{}{1.5, 1e-7}},
+			{"%g %v", []interface{}{math.NaN(), []float64{1, math.NaN()}}},
+			{"%T took %D from %ip", []interface{}{
+				time.Date(2019, 4, 1, 12, 30, 0, 0, time.UTC), 1500 * time.Millisecond,
+				netip.MustParseAddr("192.0.2.1"),
+			}},
+			{"%{DateOnly}T", []interface{}{time.Date(2019, 4, 1, 0, 0, 0, 0, time.Local)}},
+		} {
+			assert.NoErrorf(t, goparse.CheckRoundTrip(c.format, c.values...),
+				"CheckRoundTrip(%s, %v) failed", c.format, c.values)
+		}
+	})
+
+	t.Run("mismatch case", func(t *testing.T) {
+		for _, c := range []struct {
+			format string
+			values []interface{}
+		}{
+			{"%s %s", []interface{}{"a b", "c"}},
+			{"%.2f", []interface{}{1.234}},
+			{"%d", []interface{}{"abc"}},
+			{"%s", []interface{}{"a", "b"}},
+			{"%s", []interface{}{nil}},
+			{"%v", []interface{}{nil}},
+			{"%T", []interface{}{"2019-04-01"}},
+			{"%T", []interface{}{time.Date(2019, 4, 1, 12, 30, 0, 5, time.UTC)}},
+			{"%g", []interface{}{math.NaN(), 1.5}},
+		} {
+			assert.Errorf(t, goparse.CheckRoundTrip(c.format, c.values...),
+				"CheckRoundTrip(%s, %v) not failed want fail", c.format, c.values)
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"%s", "Hello"},
+		{"Hello %s! I'm %s", "Hello Yukari! I'm Sonoko"},
+		{"%d-%x-%o-%b", "12-ff-17-101"},
+		{"%5.2f %e %t %c %U %q", "  1.50 1e+10 true x U+0041 \"a\""},
+		{"%v %+v %#v", "{a 1} {Name:a} main.x{Name:\"a\"}"},
+		{"%v", "map[a:[1 2] b:[]]"},
+		{"%T took %D from %ip", "2019-04-01T12:30:00Z took 1.5s from 192.0.2.1"},
+		{"%{name}s=%{unix}T", "iori=1554121800"},
+		{"%%%s%", "%a"},
+		{"%", ""},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, format, str string) {
+		r := goparse.Parse(format, str)
+		for i := 0; i < r.Len(); i++ {
+			start, end := r.Span(i)
+			if str[start:end] != r.Raw(i) {
+				t.Fatalf("Span(%d) is %d:%d, but Raw is %q", i, start, end, r.Raw(i))
+			}
+			var s string
+			var v interface{}
+			_ = r.InsertOnly(uint(i), &s)
+			_ = r.InsertOnly(uint(i), &v)
+		}
+		_ = r.Decode(&struct{ A, B string }{})
+		_ = r.Map()
+		_, _ = goparse.FindAll(format, str)
+	})
+}
+
+func FuzzCheckRoundTrip(f *testing.F) {
+	f.Add("iori", 17, true, 1.5)
+	f.Add("a b", -1, false, 0.0)
+	f.Fuzz(func(t *testing.T, s string, n int, b bool, x float64) {
+		for _, format := range []string{"%q %d %t %v", "%q,%d,%t,%g"} {
+			if err := goparse.CheckRoundTrip(format, s, n, b, x); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
 }
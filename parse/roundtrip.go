@@ -0,0 +1,77 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// CheckRoundTrip formats values with format in the same way as Format.Sprint,
+// parses the text back and reports error when a value is not read back as it is.
+// The value is read into a new variable of its type. NaN is read back as NaN,
+// and time.Time is compared by Equal.
+//
+//	CheckRoundTrip("%s=%d", "id", 17) => nil
+//	CheckRoundTrip("%s %s", "a b", "c") => error, "a b" is read as "a"
+func CheckRoundTrip(format string, values ...interface{}) error {
+	f, err := Compile(format)
+	if err != nil {
+		return err
+	}
+	str, err := f.Sprint(values...)
+	if err != nil {
+		return fmt.Errorf("Sprint(%q) failed: %s", format, err)
+	}
+
+	r := f.Parse(str).(result)
+	if r.err != nil {
+		return fmt.Errorf("Parse(%q, %q) failed: %s", format, str, r.err)
+	}
+	for i, v := range values {
+		if v == nil {
+			return fmt.Errorf("value %d is nil, its type is unknown", i)
+		}
+		dest := reflect.New(reflect.TypeOf(v))
+		if err := r.InsertOnly(uint(i), dest.Interface()); err != nil {
+			return fmt.Errorf("value %d %#v can't be read back from %q: %s", i, v, r.Raw(i), err)
+		}
+		if actual := dest.Elem().Interface(); !equal(v, actual) {
+			return fmt.Errorf("value %d %#v is read back as %#v from %q in %q",
+				i, v, actual, r.Raw(i), str)
+		}
+	}
+	return nil
+}
+
+// equal reports whether actual is the same as v like reflect.DeepEqual,
+// but NaN equals NaN and time.Time is compared by Equal
+func equal(v, actual interface{}) bool {
+	if t, ok := v.(time.Time); ok {
+		at, ok := actual.(time.Time)
+		return ok && t.Equal(at)
+	}
+	a, b := reflect.ValueOf(v), reflect.ValueOf(actual)
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(a.Float()) {
+			return math.IsNaN(b.Float())
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equal(a.Index(i).Interface(), b.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(v, actual)
+}
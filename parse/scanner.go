@@ -0,0 +1,117 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Scanner reads lines from io.Reader and parses each of them with a format.
+//
+//	s := NewScanner(file, MustCompile("%s %s %d"))
+//	for s.Scan() {
+//		var method, path string
+//		var status int
+//		_ = s.Result().Insert(&method, &path, &status)
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+//
+// By default, Scan stops at the first line which doesn't match the format,
+// and Err returns the error. With SkipInvalid, the line is skipped and counted.
+type Scanner struct {
+	f  *Format
+	sc *bufio.Scanner
+
+	skip    bool
+	skipped int
+
+	line int
+	text string
+	res  Result
+	err  error
+}
+
+// NewScanner returns a Scanner which reads lines from r and parses them with f.
+// The max line size is bufio.MaxScanTokenSize, it can be changed by MaxLineSize.
+func NewScanner(r io.Reader, f *Format) *Scanner {
+	return &Scanner{f: f, sc: bufio.NewScanner(r)}
+}
+
+// MaxLineSize sets the max size of a line in bytes.
+// Scan stops with bufio.ErrTooLong when a line is longer than it.
+// It panics if it's called after scanning has started.
+func (s *Scanner) MaxLineSize(n int) {
+	s.sc.Buffer(nil, n)
+}
+
+// SkipInvalid sets whether Scan skips the lines which don't match the format.
+// The number of skipped lines is returned by Skipped.
+func (s *Scanner) SkipInvalid(skip bool) {
+	s.skip = skip
+}
+
+// Scan reads lines until it finds the line which matches the format,
+// then the result is available by Result.
+// It returns false when the input ends or an error happens.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.sc.Scan() {
+		s.line++
+		s.text = s.sc.Text()
+		s.res = s.f.Parse(s.text)
+		err := s.res.(result).err
+		if err == nil {
+			return true
+		}
+		if !s.skip {
+			s.err = fmt.Errorf("goparse: line %d: %w", s.line, err)
+			return false
+		}
+		s.skipped++
+	}
+	s.err = s.sc.Err()
+	s.res = nil
+	return false
+}
+
+// Result returns the result of the line read by the last Scan.
+func (s *Scanner) Result() Result {
+	return s.res
+}
+
+// Decode stores values of the line read by the last Scan into the struct v points to.
+// See Result.Decode about how values are mapped to fields.
+func (s *Scanner) Decode(v interface{}) error {
+	if s.res == nil {
+		return fmt.Errorf("goparse: no line is scanned")
+	}
+	return s.res.Decode(v)
+}
+
+// Line returns the 1-based line number of the line read by the last Scan.
+func (s *Scanner) Line() int {
+	return s.line
+}
+
+// Text returns the line read by the last Scan, without the newline.
+func (s *Scanner) Text() string {
+	return s.text
+}
+
+// Skipped returns the number of lines skipped by SkipInvalid.
+func (s *Scanner) Skipped() int {
+	return s.skipped
+}
+
+// Err returns the first error happened in Scan.
+// The error of the line which doesn't match the format wraps *ParseError,
+// and it's nil when the input ends successfully.
+func (s *Scanner) Err() error {
+	return s.err
+}
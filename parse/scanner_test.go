@@ -0,0 +1,122 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+const accessLog = `GET /index.html 200
+POST /login 302
+broken line
+GET /favicon.ico 404
+`
+
+type access struct {
+	Method string
+	Path   string
+	Status int
+}
+
+func TestScanner(t *testing.T) {
+	f := goparse.MustCompile("%s %s %d")
+
+	t.Run("stops at the invalid line", func(t *testing.T) {
+		s := goparse.NewScanner(strings.NewReader(accessLog), f)
+		var res []access
+		for s.Scan() {
+			var a access
+			err := s.Decode(&a)
+			assert.NoError(t, err)
+			res = append(res, a)
+		}
+		assert.Equal(t, []access{
+			{"GET", "/index.html", 200},
+			{"POST", "/login", 302},
+		}, res)
+
+		err := s.Err()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "line 3")
+		var perr *goparse.ParseError
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, 3, s.Line())
+		assert.Equal(t, "broken line", s.Text())
+		assert.False(t, s.Scan())
+	})
+
+	t.Run("skips the invalid line", func(t *testing.T) {
+		s := goparse.NewScanner(strings.NewReader(accessLog), f)
+		s.SkipInvalid(true)
+		var lines []int
+		var paths []string
+		for s.Scan() {
+			lines = append(lines, s.Line())
+			var method, path string
+			var status int
+			err := s.Result().Insert(&method, &path, &status)
+			assert.NoError(t, err)
+			paths = append(paths, path)
+		}
+		assert.NoError(t, s.Err())
+		assert.Equal(t, []int{1, 2, 4}, lines)
+		assert.Equal(t, []string{"/index.html", "/login", "/favicon.ico"}, paths)
+		assert.Equal(t, 1, s.Skipped())
+	})
+
+	t.Run("CRLF and no newline at the end", func(t *testing.T) {
+		s := goparse.NewScanner(strings.NewReader("a=1\r\nb=2"), goparse.MustCompile("%s=%d"))
+		var names []string
+		for s.Scan() {
+			names = append(names, s.Text())
+		}
+		assert.NoError(t, s.Err())
+		assert.Equal(t, []string{"a=1", "b=2"}, names)
+	})
+
+	t.Run("max line size", func(t *testing.T) {
+		long := "GET /" + strings.Repeat("a", 100) + " 200\n"
+		s := goparse.NewScanner(strings.NewReader(long), f)
+		s.MaxLineSize(64)
+		assert.False(t, s.Scan())
+		assert.Equal(t, bufio.ErrTooLong, s.Err())
+
+		s = goparse.NewScanner(strings.NewReader(long), f)
+		s.MaxLineSize(128)
+		assert.True(t, s.Scan())
+	})
+
+	t.Run("decode before scan", func(t *testing.T) {
+		s := goparse.NewScanner(strings.NewReader(accessLog), f)
+		var a access
+		assert.Error(t, s.Decode(&a))
+	})
+}
+
+func ExampleScanner() {
+	s := goparse.NewScanner(strings.NewReader(accessLog), goparse.MustCompile("%s %s %d"))
+	s.SkipInvalid(true)
+	for s.Scan() {
+		var a access
+		if err := s.Decode(&a); err != nil {
+			panic(err)
+		}
+		fmt.Println(s.Line(), a.Path, a.Status)
+	}
+	if err := s.Err(); err != nil {
+		panic(err)
+	}
+	fmt.Println("skipped:", s.Skipped())
+	// Output:
+	// 1 /index.html 200
+	// 2 /login 302
+	// 4 /favicon.ico 404
+	// skipped: 1
+}
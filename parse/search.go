@@ -0,0 +1,113 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Search finds the first text in str which matches format.
+// Unlike Parse, format doesn't need to match the whole str.
+// Note that a verb at the end of format consumes the rest of str.
+//
+//	Search("<title>%s</title>", "<html><title>Hello</title></html>") => "Hello"
+func Search(format, str string) Result {
+	f, err := Compile(format)
+	if err != nil {
+		return result{err: err}
+	}
+	return f.Search(str)
+}
+
+// FindAll returns every non-overlapping text in str which matches format,
+// in the order they appear. It returns error only when format is invalid.
+func FindAll(format, str string) ([]Result, error) {
+	f, err := Compile(format)
+	if err != nil {
+		return nil, err
+	}
+	return f.FindAll(str), nil
+}
+
+// Search finds the first text in str which matches the compiled format.
+func (f *Format) Search(str string) Result {
+	m := newMatcher(f, str, false)
+	if r, ok := m.search(0); ok {
+		return r
+	}
+	return result{err: m.err.locate(str)}
+}
+
+// FindAll returns every non-overlapping text in str which matches
+// the compiled format.
+func (f *Format) FindAll(str string) []Result {
+	m := newMatcher(f, str, false)
+	var res []Result
+	prevEnd := -1
+	for pos := 0; pos <= len(str); {
+		r, ok := m.search(pos)
+		if !ok {
+			break
+		}
+		if r.start != r.end || r.start != prevEnd {
+			// The empty match right after the previous match is ignored
+			res = append(res, r)
+			prevEnd = r.end
+		}
+
+		pos = r.end
+		if r.end == r.start {
+			// Empty match, go forward not to find it again
+			if pos == len(str) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(str[pos:])
+			pos += size
+		}
+	}
+	return res
+}
+
+// search finds the first position from pos where the program matches
+func (m *matcher) search(pos int) (result, bool) {
+	for start := pos; start <= len(m.str); {
+		if len(m.prog) > 0 && m.prog[0].spec == nil {
+			// Skips to the head literal
+			lit := m.prog[0].literal
+			i := strings.Index(m.str[start:], lit)
+			if i == -1 {
+				m.fail(start, &ParseError{
+					Kind:         LiteralMismatch,
+					FormatOffset: m.prog[0].offset,
+					Expected:     lit,
+					Found:        truncate(m.str[start:]),
+				})
+				break
+			}
+			start += i
+		}
+
+		if m.match(0, start) {
+			r := result{values: m.resolve(), names: m.names, start: start, end: m.end}
+			m.values = nil
+			return r, true
+		}
+
+		if start == len(m.str) || m.prog[0].spec != nil && m.prog[0].spec.freeText() {
+			// The free text at the head from a later start ends at one of
+			// the positions tried from this start, so it never matches either
+			break
+		}
+		_, size := utf8.DecodeRuneInString(m.str[start:])
+		start += size
+	}
+	return result{}, false
+}
+
+// freeText reports whether the verb reads any text, so that its candidates
+// from a position are the ends of the text, which don't depend on the start.
+func (sp *spec) freeText() bool {
+	return sp.custom == nil && sp.word == "" && sp.width <= 0 && sp.prec < 0 &&
+		(sp.verb == 's' || sp.verb == 'v' && !sp.sharp)
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `<!doctype html>
+<html>
+<head>
+    <title>Example Domain</title>
+    <meta charset="utf-8" />
+</head>
+</html>`
+
+func TestSearch(t *testing.T) {
+
+	t.Run("the text in the middle of str", func(t *testing.T) {
+		var title string
+		r := goparse.Search("<title>%s</title>", page)
+		err := r.Insert(&title)
+		assert.NoError(t, err)
+		assert.Equal(t, "Example Domain", title)
+
+		start, end := r.Bounds()
+		assert.Equal(t, "<title>Example Domain</title>", page[start:end])
+	})
+
+	t.Run("the first match is returned", func(t *testing.T) {
+		var id int
+		r := goparse.Search("id=%d;", "name=iori; id=12; id=34;")
+		err := r.Insert(&id)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, id)
+	})
+
+	t.Run("the format starts with a verb", func(t *testing.T) {
+		var n int
+		var unit string
+		err := goparse.Search("%d%s;", "size: 120kb; 3mb;").Insert(&n, &unit)
+		assert.NoError(t, err)
+		assert.Equal(t, 120, n)
+		assert.Equal(t, "kb", unit)
+	})
+
+	t.Run("the verb at the end consumes the rest", func(t *testing.T) {
+		var s string
+		err := goparse.Search("name=%s", "id=1 name=sonoko mizuki").Insert(&s)
+		assert.NoError(t, err)
+		assert.Equal(t, "sonoko mizuki", s)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var title string
+		err := goparse.Search("<h1>%s</h1>", page).Insert(&title)
+		var perr *goparse.ParseError
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, goparse.LiteralMismatch, perr.Kind)
+		assert.Equal(t, "<h1>", perr.Expected)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var s string
+		err := goparse.Search("%s%s", page).Insert(&s)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous")
+	})
+}
+
+func TestFindAll(t *testing.T) {
+
+	t.Run("all matches", func(t *testing.T) {
+		str := "<li>apple</li><li>banana</li><li>cherry</li>"
+		results, err := goparse.FindAll("<li>%s</li>", str)
+		assert.NoError(t, err)
+		assert.Len(t, results, 3)
+
+		expected := []string{"apple", "banana", "cherry"}
+		for i, r := range results {
+			var s string
+			assert.NoError(t, r.Insert(&s))
+			assert.Equal(t, expected[i], s)
+
+			start, end := r.Bounds()
+			assert.Equal(t, "<li>"+expected[i]+"</li>", str[start:end])
+		}
+	})
+
+	t.Run("non-overlapping", func(t *testing.T) {
+		results, err := goparse.FindAll("a%da", "a1a2a3a")
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		var n1, n2 int
+		assert.NoError(t, results[0].Insert(&n1))
+		assert.NoError(t, results[1].Insert(&n2))
+		assert.Equal(t, 1, n1)
+		assert.Equal(t, 3, n2)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		results, err := goparse.FindAll("<li>%s</li>", page)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("no empty match at the end of the previous match", func(t *testing.T) {
+		results, err := goparse.FindAll("%s", "abc")
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("the format starts with a verb", func(t *testing.T) {
+		str := strings.Repeat("word, other 12 text; ", 5000)
+		results, err := goparse.FindAll("%s,%d;", str)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+
+		results, err = goparse.FindAll("%s,%d;", strings.Repeat("ab,12;", 5000))
+		assert.NoError(t, err)
+		assert.Len(t, results, 5000)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := goparse.FindAll("%d%d", page)
+		assert.Error(t, err)
+	})
+}
+
+func ExampleSearch() {
+	str := "<html><head><title>Example Domain</title></head></html>"
+	var title string
+	_ = goparse.Search("<title>%s</title>", str).Insert(&title)
+	fmt.Println(title)
+	// Output:
+	// Example Domain
+}
+
+func ExampleFindAll() {
+	results, _ := goparse.FindAll("[%s:%d]", "[iori:17] [sonoko:16] [yukari:17]")
+	for _, r := range results {
+		var name string
+		var age int
+		_ = r.Insert(&name, &age)
+		fmt.Println(name, age)
+	}
+	// Output:
+	// iori 17
+	// sonoko 16
+	// yukari 17
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Set holds named formats and finds the first one which matches the input.
+// The formats are indexed by their leading literals, so the formats whose
+// leading literal doesn't match the input are not tried.
+// The format which starts with a verb is indexed by its first literal,
+// and it's tried only when the input contains the literal.
+//
+//	s := NewSet()
+//	_ = s.Add("access", "GET %s %d")
+//	_ = s.Add("error", "[error] %s")
+//	name, r := s.Match("[error] timeout") => "error"
+//
+// Add must not be called concurrently with Match, but Match is safe for
+// concurrent use by multiple goroutines.
+type Set struct {
+	names   []string
+	formats []*Format
+	index   *prefixNode
+	// inner is the indexes of formats starting with a verb by their first literal
+	inner map[string][]int
+}
+
+// prefixNode is the node of the trie of the leading literals
+type prefixNode struct {
+	children map[byte]*prefixNode
+	// formats are the indexes of formats whose leading literal ends at the node
+	formats []int
+}
+
+// NewSet returns an empty Set.
+func NewSet() *Set {
+	return &Set{index: &prefixNode{}}
+}
+
+// Add compiles format and adds it to the set with name.
+// The formats are tried in order they're added.
+func (s *Set) Add(name, format string, opts ...Option) error {
+	f, err := Compile(format, opts...)
+	if err != nil {
+		return err
+	}
+	return s.AddFormat(name, f)
+}
+
+// AddFormat adds the compiled format to the set with name.
+func (s *Set) AddFormat(name string, f *Format) error {
+	for _, n := range s.names {
+		if n == name {
+			return fmt.Errorf("goparse: format named %q is already added", name)
+		}
+	}
+	s.names = append(s.names, name)
+	s.formats = append(s.formats, f)
+
+	i := len(s.formats) - 1
+	prefix := f.prefix()
+	if lit := f.firstLiteral(); prefix == "" && lit != "" {
+		if s.inner == nil {
+			s.inner = make(map[string][]int)
+		}
+		s.inner[lit] = append(s.inner[lit], i)
+		return nil
+	}
+
+	node := s.index
+	for _, c := range []byte(prefix) {
+		child, ok := node.children[c]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[byte]*prefixNode)
+			}
+			child = &prefixNode{}
+			node.children[c] = child
+		}
+		node = child
+	}
+	node.formats = append(node.formats, i)
+	return nil
+}
+
+// Len returns the number of formats in the set.
+func (s *Set) Len() int {
+	return len(s.formats)
+}
+
+// Match returns the name and the result of the first format which matches
+// the whole str. When no format matches, name is "" and the result has
+// the error of the format which matched the longest part of str.
+func (s *Set) Match(str string) (name string, r Result) {
+	var best *ParseError
+	for _, i := range s.candidates(str) {
+		res := s.formats[i].Parse(str).(result)
+		if res.err == nil {
+			return s.names[i], res
+		}
+		if perr, ok := res.err.(*ParseError); ok && (best == nil || perr.Offset > best.Offset) {
+			best = perr
+		}
+	}
+	if best != nil {
+		return "", result{err: best}
+	}
+	return "", result{err: fmt.Errorf("goparse: no format in the set matches %q", truncate(str))}
+}
+
+// candidates returns the indexes of formats whose leading literal is
+// a prefix of str, or whose first literal is in str, in order they're added.
+func (s *Set) candidates(str string) []int {
+	var res []int
+	for lit, formats := range s.inner {
+		// The formats sharing the literal are checked at once
+		if strings.Contains(str, lit) {
+			res = append(res, formats...)
+		}
+	}
+
+	node := s.index
+	res = append(res, node.formats...)
+	for i := 0; i < len(str); i++ {
+		node = node.children[str[i]]
+		if node == nil {
+			break
+		}
+		res = append(res, node.formats...)
+	}
+	sort.Ints(res)
+	return res
+}
+
+// prefix returns the literal at the head of the format
+func (f *Format) prefix() string {
+	if len(f.prog) > 0 && f.prog[0].spec == nil {
+		return f.prog[0].literal
+	}
+	return ""
+}
+
+// firstLiteral returns the first literal in the format
+func (f *Format) firstLiteral() string {
+	for _, in := range f.prog {
+		if in.spec == nil {
+			return in.literal
+		}
+	}
+	return ""
+}
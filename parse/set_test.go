@@ -0,0 +1,118 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	s := goparse.NewSet()
+	assert.NoError(t, s.Add("get", "GET %s %d"))
+	assert.NoError(t, s.Add("getAll", "GET * %d"))
+	assert.NoError(t, s.Add("post", "POST %s %d"))
+	assert.NoError(t, s.Add("error", "[error] %s"))
+	assert.NoError(t, s.Add("kv", "%s=%d"))
+	assert.NoError(t, s.AddFormat("any", goparse.MustCompile("%s")))
+	assert.Equal(t, 6, s.Len())
+
+	t.Run("the first matching format", func(t *testing.T) {
+		for _, c := range []struct {
+			str  string
+			name string
+		}{
+			{"GET /index.html 200", "get"},
+			{"GET * 200", "get"},
+			{"POST /login 302", "post"},
+			{"[error] timeout", "error"},
+			{"id=17", "kv"},
+			{"GET", "any"},
+			{"", "any"},
+		} {
+			name, _ := s.Match(c.str)
+			assert.Equalf(t, c.name, name, "Match(%s)", c.str)
+		}
+
+		name, r := s.Match("POST /login 302")
+		assert.Equal(t, "post", name)
+		var path string
+		var status int
+		assert.NoError(t, r.Insert(&path, &status))
+		assert.Equal(t, "/login", path)
+		assert.Equal(t, 302, status)
+	})
+
+	t.Run("no format matches", func(t *testing.T) {
+		s := goparse.NewSet()
+		assert.NoError(t, s.Add("get", "GET %s %d"))
+		assert.NoError(t, s.Add("post", "POST %s %d"))
+
+		name, r := s.Match("GET /index.html OK")
+		assert.Equal(t, "", name)
+		assert.Equal(t, 0, r.Len())
+		var perr *goparse.ParseError
+		err := r.Insert()
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, 16, perr.Offset)
+
+		name, r = s.Match("DELETE /index.html 200")
+		assert.Equal(t, "", name)
+		assert.Error(t, r.Insert())
+	})
+
+	t.Run("the format starts with a verb", func(t *testing.T) {
+		s := goparse.NewSet()
+		assert.NoError(t, s.Add("kv", "%s=%d"))
+		assert.NoError(t, s.Add("get", "GET %s"))
+		assert.NoError(t, s.Add("pair", "%s: %s;"))
+		assert.NoError(t, s.Add("ratio", "%d/%d"))
+
+		for _, c := range []struct {
+			str  string
+			name string
+		}{
+			{"GET id=17", "kv"},
+			{"GET /index.html", "get"},
+			{"name: iori;", "pair"},
+			{"3/4", "ratio"},
+			{"iori", ""},
+		} {
+			name, _ := s.Match(c.str)
+			assert.Equalf(t, c.name, name, "Match(%s)", c.str)
+		}
+	})
+
+	t.Run("invalid case", func(t *testing.T) {
+		assert.Error(t, s.Add("get", "GET %s"))
+		assert.Error(t, s.Add("broken", "%"))
+	})
+}
+
+func ExampleSet() {
+	s := goparse.NewSet()
+	_ = s.Add("access", "%s %s %d")
+	_ = s.Add("error", "[error] %s")
+
+	for _, line := range []string{"GET /index.html 200", "[error] connection refused"} {
+		name, r := s.Match(line)
+		switch name {
+		case "access":
+			var method, path string
+			var status int
+			_ = r.Insert(&method, &path, &status)
+			fmt.Println(name, path, status)
+		case "error":
+			var msg string
+			_ = r.Insert(&msg)
+			fmt.Println(name, msg)
+		}
+	}
+	// Output:
+	// access /index.html 200
+	// error connection refused
+}
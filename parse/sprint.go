@@ -0,0 +1,185 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Sprint formats values with the compiled format, it's the opposite of Parse.
+// The values are formatted in the same way as fmt.Sprintf, and %T and %D
+// are formatted as time.Time and time.Duration. {:n} and {:%} of the brace
+// syntax are formatted like 1,234 and 12.5%.
+//
+// The text is parsed back with the format, and Sprint returns error when
+// a verb would read the text different from its value, e.g. the value of %s
+// contains the literal following it. The value which doesn't fit the verb,
+// which fmt writes like "%!s(int=42)", is error as well.
+//
+//	Sprint("%s=%d", "id", 17) => "id=17"
+//	Sprint("%s %s", "a b", "c") => error, "a b" is read back as "a"
+func (f *Format) Sprint(values ...interface{}) (string, error) {
+	if len(values) != len(f.names) {
+		return "", fmt.Errorf(
+			"expected %d values in Sprint, not %d", len(f.names), len(values))
+	}
+
+	var b strings.Builder
+	pieces := make([]string, 0, len(values))
+	for _, in := range f.prog {
+		if in.spec == nil {
+			b.WriteString(in.literal)
+			continue
+		}
+		s, err := in.spec.sprint(values[len(pieces)])
+		if err != nil {
+			return "", fmt.Errorf("value %d for %s: %s", len(pieces), in.spec.text, err)
+		}
+		pieces = append(pieces, s)
+		b.WriteString(s)
+	}
+
+	str := b.String()
+	r := f.Parse(str).(result)
+	if r.err != nil {
+		return "", fmt.Errorf("%q can't be parsed back: %s", str, r.err)
+	}
+	for i, v := range r.values {
+		if v.raw != pieces[i] {
+			return "", fmt.Errorf(
+				"value %d %q is read back as %q in %q", i, pieces[i], v.raw, str)
+		}
+	}
+	return str, nil
+}
+
+// Marshal formats the fields of the struct v with the compiled format.
+// The value of the named verb like %{user}s is the field tagged
+// `goparse:"user"`, or the field named User, and the values of unnamed verbs
+// are the rest of fields in order, in the same way as Result.Decode.
+func (f *Format) Marshal(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("invalid source: expected struct or pointer to struct, actual %T", v)
+	}
+
+	targets, err := verbFields(rv.Type(), f.names)
+	if err != nil {
+		return "", err
+	}
+	values := make([]interface{}, len(targets))
+	for i, t := range targets {
+		if t == nil {
+			return "", fmt.Errorf("%s has no field named %s", rv.Type().String(), f.names[i])
+		}
+		if !t.exported {
+			return "", fmt.Errorf("field %s of %s is not exposed",
+				rv.Type().Field(t.index).Name, rv.Type().String())
+		}
+		values[i] = rv.Field(t.index).Interface()
+	}
+	return f.Sprint(values...)
+}
+
+// formatter is the verb which writes the value in its own way,
+// like the percentage of the brace syntax
+type formatter interface {
+	format(v interface{}) (string, error)
+}
+
+// sprint formats v with the verb
+func (sp *spec) sprint(v interface{}) (string, error) {
+	if sp.width <= 0 || sp.fill == "" && !sp.center && sp.custom == nil &&
+		sp.word == "" && sp.verb != 'T' && sp.verb != 'D' {
+		return sp.sprintValue(v)
+	}
+	// fmt pads only with spaces on either side,
+	// and it doesn't pad the text of other verbs
+	plain := *sp
+	plain.width = -1
+	s, err := plain.sprintValue(v)
+	if err != nil {
+		return "", err
+	}
+	return sp.pad(s), nil
+}
+
+// pad pads s to the width with the fill, s is right-aligned by default
+func (sp *spec) pad(s string) string {
+	n := sp.width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	fill := " "
+	if sp.fill != "" {
+		fill = sp.fill
+	}
+	switch {
+	case sp.center:
+		return strings.Repeat(fill, n/2) + s + strings.Repeat(fill, n-n/2)
+	case sp.minus:
+		return s + strings.Repeat(fill, n)
+	}
+	return strings.Repeat(fill, n) + s
+}
+
+// sprintValue formats v with the verb, fmt pads it only when the verb is of fmt
+func (sp *spec) sprintValue(v interface{}) (string, error) {
+	if f, ok := sp.custom.(formatter); ok {
+		return f.format(v)
+	}
+	switch {
+	case sp.custom != nil || sp.word != "":
+		return fmt.Sprint(v), nil
+	case sp.verb == 'T':
+		t, ok := v.(time.Time)
+		if !ok {
+			return "", fmt.Errorf("expected time.Time, actual %T", v)
+		}
+		return formatTime(t, sp.arg), nil
+	case sp.verb == 'D':
+		d, ok := v.(time.Duration)
+		if !ok {
+			return "", fmt.Errorf("expected time.Duration, actual %T", v)
+		}
+		return d.String(), nil
+	}
+	s := fmt.Sprintf(sp.directive(), v)
+	if strings.Contains(s, "%!"+string(sp.verb)+"(") {
+		// fmt writes the value which doesn't fit the verb like "%!s(int=42)",
+		// %s and %v would read it back as it is
+		return "", fmt.Errorf("%T can't be formatted by %s: %s", v, sp.text, s)
+	}
+	return s, nil
+}
+
+// directive returns the verb for fmt without the name like "%-5d"
+func (sp *spec) directive() string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, flag := range []struct {
+		on bool
+		c  byte
+	}{{sp.minus, '-'}, {sp.plus, '+'}, {sp.space, ' '}, {sp.zero, '0'}, {sp.sharp, '#'}} {
+		if flag.on {
+			b.WriteByte(flag.c)
+		}
+	}
+	if sp.width >= 0 {
+		b.WriteString(strconv.Itoa(sp.width))
+	}
+	if sp.prec >= 0 {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(sp.prec))
+	}
+	b.WriteByte(sp.verb)
+	return b.String()
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+// broken panics in String, fmt writes it like "%!v(PANIC=String method: broken)"
+type broken struct{}
+
+func (broken) String() string {
+	panic("broken")
+}
+
+func TestFormat_Sprint(t *testing.T) {
+
+	t.Run("The opposite of Parse", func(t *testing.T) {
+		at := time.Date(2019, 4, 1, 12, 30, 0, 0, time.UTC)
+		for _, c := range []struct {
+			format   string
+			values   []interface{}
+			expected string
+		}{
+			{"%s=%d", []interface{}{"id", 17}, "id=17"},
+			{"[%5d|%-4s|%x]", []interface{}{42, "ab", 255}, "[   42|ab  |ff]"},
+			{"%.2f %t %q", []interface{}{1.5, true, "a b"}, "1.50 true \"a b\""},
+			{"%v %v", []interface{}{[]int{1, 2}, map[string]int{"a": 1}}, "[1 2] map[a:1]"},
+			{"%T took %D", []interface{}{at, 1500 * time.Millisecond}, "2019-04-01T12:30:00Z took 1.5s"},
+			{"%{DateOnly}T %{unix}T", []interface{}{at, at}, "2019-04-01 1554121800"},
+			{"from %ip", []interface{}{netip.MustParseAddr("192.0.2.1")}, "from 192.0.2.1"},
+			{"%{user}s!", []interface{}{"iori"}, "iori!"},
+		} {
+			f := goparse.MustCompile(c.format)
+			str, err := f.Sprint(c.values...)
+			assert.NoErrorf(t, err, "Sprint(%s) failed", c.format)
+			assert.Equal(t, c.expected, str)
+			assert.Equal(t, len(c.values), f.Parse(str).Len())
+		}
+	})
+
+	t.Run("padded by hand", func(t *testing.T) {
+		str, err := goparse.MustCompile("[%12D]").Sprint(1500 * time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, "[        1.5s]", str)
+
+		str, err = goparse.MustCompile("[%-12ip]").Sprint(netip.MustParseAddr("::1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "[::1         ]", str)
+	})
+
+	t.Run("unparseable values", func(t *testing.T) {
+		for _, c := range []struct {
+			format string
+			values []interface{}
+		}{
+			{"%s %s", []interface{}{"a b", "c"}},
+			{"%s,%s", []interface{}{"a,b", "c"}},
+			{"%d", []interface{}{"abc"}},
+			{"%s=%d", []interface{}{"id"}},
+			{"%T", []interface{}{"2019-04-01"}},
+			{"%D", []interface{}{1500}},
+			{"%s", []interface{}{42}},
+			{"%s", []interface{}{nil}},
+			{"%v", []interface{}{broken{}}},
+			{"[%5s]", []interface{}{true}},
+			{"%s", []interface{}{[]int{1, 2}}},
+			{"%d", []interface{}{1.5}},
+		} {
+			_, err := goparse.MustCompile(c.format).Sprint(c.values...)
+			assert.Errorf(t, err, "Sprint(%s, %v) not failed want fail", c.format, c.values)
+		}
+	})
+}
+
+func TestFormat_Marshal(t *testing.T) {
+
+	type access struct {
+		User   string `goparse:"user"`
+		Method string
+		Status int
+		Note   string `goparse:"-"`
+	}
+
+	t.Run("by name and position", func(t *testing.T) {
+		f := goparse.MustCompile("%s %d by %{user}s")
+		v := access{User: "iori", Method: "GET", Status: 200, Note: "ignored"}
+		str, err := f.Marshal(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "GET 200 by iori", str)
+
+		str, err = f.Marshal(&v)
+		assert.NoError(t, err)
+		assert.Equal(t, "GET 200 by iori", str)
+
+		var res access
+		assert.NoError(t, f.Parse(str).Decode(&res))
+		v.Note = ""
+		assert.Equal(t, v, res)
+	})
+
+	t.Run("invalid case", func(t *testing.T) {
+		_, err := goparse.MustCompile("%{name}s").Marshal(access{})
+		assert.Error(t, err)
+
+		_, err = goparse.MustCompile("%s %s %s %s").Marshal(access{})
+		assert.Error(t, err)
+
+		_, err = goparse.MustCompile("%s").Marshal("iori")
+		assert.Error(t, err)
+
+		type secret struct {
+			key string
+		}
+		_, err = goparse.MustCompile("%s").Marshal(secret{"abc"})
+		assert.Error(t, err)
+	})
+}
+
+func ExampleFormat_Sprint() {
+	f := goparse.MustCompile("%s,%s")
+	str, err := f.Sprint("iori", "sonoko")
+	fmt.Println(str, err)
+
+	_, err = f.Sprint("iori,yukari", "sonoko")
+	fmt.Println(err)
+	// Output:
+	// iori,sonoko <nil>
+	// value 0 "iori,yukari" is read back as "iori" in "iori,yukari,sonoko"
+}
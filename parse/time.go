@@ -0,0 +1,147 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// timeLayouts are the names of layouts which can be the argument of %T
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// unixTimes are the layouts of %T for the integer since the Unix epoch
+var unixTimes = map[string]func(n int64) time.Time{
+	"unix":      func(n int64) time.Time { return time.Unix(n, 0) },
+	"unixmilli": time.UnixMilli,
+	"unixmicro": time.UnixMicro,
+	"unixnano":  func(n int64) time.Time { return time.Unix(0, n) },
+}
+
+// formatTime formats t in layout of %T, it's the opposite of scanTime
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixmicro":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case "unixnano":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	return t.Format(layout)
+}
+
+// timeLayout returns the layout of %T written as arg.
+// arg is the name of the layout like RFC3339, or the layout itself.
+// It's RFC3339 when arg is empty.
+func timeLayout(arg string) string {
+	if arg == "" {
+		return time.RFC3339
+	}
+	if layout, ok := timeLayouts[arg]; ok {
+		return layout
+	}
+	return arg
+}
+
+// layoutRef is the time to check the layout has elements of time.
+// Each element is different from the time read from no element.
+var layoutRef = time.Date(2019, 4, 3, 13, 24, 35, 123456789, time.FixedZone("JST", 9*60*60))
+
+// checkLayout returns error when the layout has no element of time like "2006",
+// e.g. the name written as the layout like %{created}T
+func checkLayout(layout string) error {
+	if _, ok := unixTimes[layout]; ok {
+		return nil
+	}
+	t, err := time.Parse(layout, layoutRef.Format(layout))
+	empty, _ := time.Parse("", "")
+	if err != nil || t.Equal(empty) {
+		return fmt.Errorf("layout %q has no element of time", layout)
+	}
+	return nil
+}
+
+// maxTimeSlack is how much the time can be longer than its layout,
+// e.g. "January" for "Jan" or the fraction of a second.
+const maxTimeSlack = 32
+
+// scanTime yields the time written in layout at the head of str, longest first.
+func scanTime(str, next string, last bool, layout string, yield func(n int, v value) bool) (bool, error) {
+	if unix, ok := unixTimes[layout]; ok {
+		return scanToken(str, next, last, "0123456789", "", func(s string) (value, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return value{}, err
+			}
+			return value{kind: reflect.Interface, value: unix(n)}, nil
+		}, yield)
+	}
+
+	max := len(str)
+	if !last && max > len(layout)+maxTimeSlack {
+		max = len(layout) + maxTimeSlack
+	}
+	min := 1
+	if last {
+		min = max
+	}
+
+	var firstErr error
+	for n := max; n >= min; n-- {
+		if next != "" && n < len(str) && str[n:n+1] != next[:1] {
+			// The following literal can't match
+			continue
+		}
+		t, err := time.Parse(layout, str[:n])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if yield(n, value{kind: reflect.Interface, value: t}) {
+			return true, nil
+		}
+	}
+	if firstErr == nil && max < min {
+		firstErr = strconv.ErrSyntax
+	}
+	return false, firstErr
+}
+
+// durationChars are the characters of time.Duration.String(),
+// it includes µ (U+00B5) and μ (U+03BC) of "µs"
+const durationChars = "0123456789.hmsun\xc2\xb5\xce\xbc"
+
+// scanDuration yields the duration like "1h2m3.5s" at the head of str, longest first.
+func scanDuration(str, next string, last bool, yield func(n int, v value) bool) (bool, error) {
+	return scanToken(str, next, last, durationChars, "", func(s string) (value, error) {
+		d, err := time.ParseDuration(s)
+		return value{kind: reflect.Interface, value: d}, err
+	}, yield)
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_time(t *testing.T) {
+	jst := time.FixedZone("", 9*60*60)
+	ts := time.Date(2019, time.March, 4, 15, 6, 7, 0, jst)
+
+	t.Run("The opposite of Format", func(t *testing.T) {
+		for _, tc := range []struct {
+			format, layout, prefix, suffix string
+		}{
+			{"at %T", time.RFC3339, "at ", ""},
+			{"at %{RFC3339}T;", time.RFC3339, "at ", ";"},
+			{"at %{2006-01-02T15:04:05Z07:00}T", time.RFC3339, "at ", ""},
+			{"[%{RFC1123Z}T] GET", time.RFC1123Z, "[", "] GET"},
+			{"[%{ANSIC}T] GET", time.ANSIC, "[", "] GET"},
+			{"%{Stamp}T host", time.Stamp, "", " host"},
+			{"%{DateTime}T|", time.DateTime, "", "|"},
+			{"%{Kitchen}T", time.Kitchen, "", ""},
+			{`[%{02/Jan/2006:15:04:05 -0700}T] "GET"`, "02/Jan/2006:15:04:05 -0700", "[", `] "GET"`},
+		} {
+			str := tc.prefix + ts.Format(tc.layout) + tc.suffix
+			var res time.Time
+			err := goparse.Parse(tc.format, str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", tc.format, str)
+			assert.Equalf(t, ts.Format(tc.layout), res.Format(tc.layout), "Parse(%s,%s)", tc.format, str)
+		}
+	})
+
+	t.Run("fraction of a second", func(t *testing.T) {
+		expected := ts.Add(123456789 * time.Nanosecond)
+		var res time.Time
+		err := goparse.Parse("%T %s", expected.Format(time.RFC3339Nano)+" ok").Insert(&res, new(string))
+		assert.NoError(t, err)
+		assert.True(t, expected.Equal(res))
+	})
+
+	t.Run("the time is followed by other verbs", func(t *testing.T) {
+		var res time.Time
+		var level string
+		var n int
+		str := ts.Format(time.RFC3339) + "INFO 3"
+		err := goparse.Parse("%T%s %d", str).Insert(&res, &level, &n)
+		assert.NoError(t, err)
+		assert.True(t, ts.Equal(res))
+		assert.Equal(t, "INFO", level)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("unix time", func(t *testing.T) {
+		for _, tc := range []struct {
+			format, str string
+		}{
+			{"t=%{unix}T;", fmt.Sprintf("t=%d;", ts.Unix())},
+			{"t=%{unixmilli}T;", fmt.Sprintf("t=%d;", ts.UnixMilli())},
+			{"t=%{unixmicro}T;", fmt.Sprintf("t=%d;", ts.UnixMicro())},
+			{"t=%{unixnano}T;", fmt.Sprintf("t=%d;", ts.UnixNano())},
+		} {
+			var res time.Time
+			err := goparse.Parse(tc.format, tc.str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", tc.format, tc.str)
+			assert.Truef(t, ts.Equal(res), "Parse(%s,%s) = %s", tc.format, tc.str, res)
+		}
+	})
+
+	t.Run("named time", func(t *testing.T) {
+		r := goparse.Parse("%{ts}{DateOnly}T %{msg}s", "2019-03-04 started")
+		var res time.Time
+		assert.NoError(t, r.Get("ts", &res))
+		assert.Equal(t, time.Date(2019, time.March, 4, 0, 0, 0, 0, time.UTC), res)
+		assert.Equal(t, []string{"ts", "msg"}, r.Names())
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		type entry struct {
+			Time    time.Time     `goparse:"time"`
+			Elapsed time.Duration `goparse:"elapsed"`
+			Path    string        `goparse:"path"`
+		}
+		var res entry
+		str := ts.Format(time.RFC3339) + " GET / 1.5ms"
+		err := goparse.Unmarshal("%{time}{RFC3339}T GET %{path}s %{elapsed}D", str, &res)
+		assert.NoError(t, err)
+		assert.True(t, ts.Equal(res.Time))
+		assert.Equal(t, "/", res.Path)
+		assert.Equal(t, 1500*time.Microsecond, res.Elapsed)
+	})
+
+	t.Run("pointer to time", func(t *testing.T) {
+		var res *time.Time
+		err := goparse.Parse("%T", ts.Format(time.RFC3339)).Insert(&res)
+		assert.NoError(t, err)
+		if assert.NotNil(t, res) {
+			assert.True(t, ts.Equal(*res))
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var res time.Time
+		err := goparse.Parse("at %T;", "at yesterday;").Insert(&res)
+		assert.Error(t, err)
+
+		err = goparse.Parse("at %{DateOnly}T", "at 2019-13-04").Insert(&res)
+		assert.Error(t, err)
+	})
+
+	t.Run("into string", func(t *testing.T) {
+		var s string
+		err := goparse.Parse("at %T", "at "+ts.Format(time.RFC3339)).Insert(&s)
+		assert.NoError(t, err)
+		assert.Equal(t, ts.Format(time.RFC3339), s)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		for _, format := range []string{
+			"%{unix}T%d",
+			"%{name}{arg}d",
+			"%{name}{}T",
+			"%{name}{RFC3339T",
+			"%{created}T",
+			"%{ts}{created}T",
+		} {
+			_, err := goparse.Compile(format)
+			assert.Errorf(t, err, "Compile(%s) not failed want fail", format)
+		}
+
+		_, err := goparse.Compile("%{created}T")
+		assert.Contains(t, err.Error(), "%{name}{layout}T")
+	})
+
+	t.Run("layouts of a single element", func(t *testing.T) {
+		for _, format := range []string{"%{2006}T", "%{Jan}T", "%{15}T", "%{-0700}T", "%{.000}T"} {
+			_, err := goparse.Compile(format)
+			assert.NoErrorf(t, err, "Compile(%s) failed", format)
+		}
+	})
+}
+
+func TestParse_duration(t *testing.T) {
+
+	t.Run("The opposite of String", func(t *testing.T) {
+		for _, expected := range []time.Duration{
+			0, time.Nanosecond, 1500 * time.Nanosecond, 2 * time.Millisecond,
+			-3 * time.Second, 90 * time.Minute, 26*time.Hour + 3*time.Second + 500*time.Millisecond,
+		} {
+			for _, format := range []string{"%D", "took %D to run", "[%D]"} {
+				str := strings.Replace(format, "%D", expected.String(), 1)
+				var res time.Duration
+				err := goparse.Parse(format, str).Insert(&res)
+				assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+				assert.Equal(t, expected, res)
+			}
+		}
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var res interface{}
+		err := goparse.Parse("%D", "1m30s").Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Second, res)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var res time.Duration
+		for _, str := range []string{"took 10 to run", "took s to run", "took 1x to run"} {
+			err := goparse.Parse("took %D to run", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s,%s) not failed want fail", "took %D to run", str)
+		}
+	})
+}
+
+func ExampleParse_time() {
+	var ts time.Time
+	var elapsed time.Duration
+	str := "2019-03-04T15:06:07Z GET /index.html 1.5ms"
+	_ = goparse.Parse("%T GET /index.html %D", str).Insert(&ts, &elapsed)
+	fmt.Println(ts.Format(time.Kitchen))
+	fmt.Println(elapsed)
+	// Output:
+	// 3:06PM
+	// 1.5ms
+}
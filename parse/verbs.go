@@ -0,0 +1,590 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ambiguous reports whether the verb a directly followed by the verb b
+// can't be split. e.g. %s%s or %d%d
+func ambiguous(a, b *spec) bool {
+	if a.width > 0 || ((a.verb == 'f' || a.verb == 'F') && a.prec >= 0) {
+		// The end of a is bounded by the width or the precision
+		return false
+	}
+	ca, cb := a.class(), b.class()
+	return ca == cb && (ca == classText || ca == classNumber)
+}
+
+type class int
+
+const (
+	classText class = iota
+	classNumber
+	classBool
+	// classBounded is the verb which knows where it ends like %c or %q
+	classBounded
+)
+
+func (sp *spec) class() class {
+	if sp.custom != nil {
+		// The custom verb returns where it ends
+		return classBounded
+	}
+	if sp.word != "" {
+		return netVerbs[sp.word].class
+	}
+	switch sp.verb {
+	case 'd', 'b', 'o', 'O', 'x', 'X', 'e', 'E', 'f', 'F', 'g', 'G', 'D':
+		return classNumber
+	case 'T':
+		if _, ok := unixTimes[sp.arg]; ok {
+			return classNumber
+		}
+		return classBounded
+	case 't':
+		return classBool
+	case 'c', 'U', 'q':
+		return classBounded
+	}
+	return classText
+}
+
+func (sp *spec) base() int {
+	switch sp.verb {
+	case 'b':
+		return 2
+	case 'o', 'O':
+		return 8
+	case 'x', 'X':
+		return 16
+	}
+	return 10
+}
+
+// scan calls yield with each text at the head of str which the verb can consume
+// and its value, in the order they should be tried, until yield returns true.
+// next is the literal text following the verb, it's empty when the verb
+// is followed by another verb. last is true when the verb is the end of format.
+//
+// scan returns error when str has no text the verb can consume.
+func (sp *spec) scan(str, next string, last bool, yield func(n int, v value) bool) (bool, error) {
+	if sp.width > 0 {
+		return sp.scanWidth(str, next, last, yield)
+	}
+	if sp.space && sp.class() == classNumber && strings.HasPrefix(str, " ") {
+		// "% d" puts a space instead of '+'
+		return sp.scanVerb(str[1:], next, last, func(n int, v value) bool {
+			return yield(n+1, v)
+		})
+	}
+	return sp.scanVerb(str, next, last, yield)
+}
+
+// scanWidth yields the field of the width with padding first,
+// and then the value wider than the width, which is not padded.
+func (sp *spec) scanWidth(str, next string, last bool, yield func(n int, v value) bool) (bool, error) {
+	w := 0
+	for i := 0; i < sp.width; i++ {
+		if w == len(str) {
+			w = -1
+			break
+		}
+		_, size := utf8.DecodeRuneInString(str[w:])
+		w += size
+	}
+
+	var err error
+	if w != -1 {
+		fill := " "
+		if sp.fill != "" {
+			fill = sp.fill
+		}
+		var text string
+		switch {
+		case sp.center:
+			text = strings.Trim(str[:w], fill)
+		case sp.minus:
+			text = strings.TrimRight(str[:w], fill)
+		default:
+			text = strings.TrimLeft(str[:w], fill)
+		}
+		ok, serr := sp.scanVerb(text, "", true, func(n int, v value) bool {
+			return n == len(text) && yield(w, v)
+		})
+		if ok {
+			return true, nil
+		}
+		err = serr
+	}
+
+	ok, serr := sp.scanVerb(str, next, last, func(n int, v value) bool {
+		return n > w && yield(n, v)
+	})
+	if err == nil {
+		err = serr
+	}
+	return ok, err
+}
+
+// scanVerb is scan without the width
+func (sp *spec) scanVerb(str, next string, last bool, yield func(n int, v value) bool) (bool, error) {
+	if sp.custom != nil {
+		return sp.scanCustom(str, next, yield)
+	}
+	if sp.word != "" {
+		return scanNet(netVerbs[sp.word], str, next, last, yield)
+	}
+	switch sp.verb {
+	case 's':
+		return scanText(str, next, last, sp.prec, func(s string) value {
+			return value{kind: reflect.String, value: s}
+		}, yield)
+	case 'v':
+		if sp.sharp {
+			conv := deferred(parseGoValue)
+			if n := goLiteral(str); n > 0 && sp.prec < 0 && (!last || n == len(str)) &&
+				(next == "" || strings.HasPrefix(str[n:], next)) {
+				// The value in Go syntax like main.sample{Name:"a b"} is tried first
+				if yield(n, conv(str[:n])) {
+					return true, nil
+				}
+			}
+			return scanText(str, next, last, sp.prec, conv, yield)
+		}
+		conv := deferred(parseValue)
+		if n := balanced(str); n > 0 && sp.prec < 0 && (!last || n == len(str)) &&
+			(next == "" || strings.HasPrefix(str[n:], next)) {
+			// The slice, map or struct like "[1 2]" is tried first
+			if yield(n, conv(str[:n])) {
+				return true, nil
+			}
+		}
+		return scanText(str, next, last, sp.prec, conv, yield)
+	case 'd', 'b', 'o', 'O':
+		// The digits are converted after the match, so that the number
+		// wider than 64 bits can be read by *big.Int
+		base := sp.base()
+		prefix := sp.prefix()
+		conv := deferred(func(s string) value {
+			return bigInteger(trimPrefix(s, prefix), base)
+		})
+		return scanToken(str, next, last, "0123456789"[:base], prefix, func(s string) (value, error) {
+			return conv(s), nil
+		}, yield)
+	case 'x', 'X':
+		prefix := sp.prefix()
+		if sp.space {
+			// "% x" writes bytes separated by spaces like "68 65 6c"
+			if scanSpacedHex(str, prefix, yield) {
+				return true, nil
+			}
+		}
+		conv := deferred(func(s string) value {
+			s = trimPrefix(s, prefix)
+			if v, err := parseHex(s); err == nil {
+				return v
+			}
+			return bigInteger(s, 16)
+		})
+		return scanToken(str, next, last, "0123456789abcdefABCDEF", prefix, func(s string) (value, error) {
+			return conv(s), nil
+		}, yield)
+	case 'c':
+		return scanRune(str, yield)
+	case 'U':
+		return scanUnicode(str, sp.sharp, yield)
+	case 'q':
+		return scanQuoted(str, yield)
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		conv := func(s string) (value, error) {
+			f, err := parseFloat(s)
+			return value{kind: reflect.Float64, value: f}, err
+		}
+		for _, w := range floatWords {
+			// NaN and Inf are written in the same way by all verbs
+			if strings.HasPrefix(str, w) {
+				v, _ := conv(w)
+				if yield(len(w), v) {
+					return true, nil
+				}
+			}
+		}
+		if (sp.verb == 'f' || sp.verb == 'F') && sp.prec >= 0 {
+			return scanFixed(str, sp.prec, sp.sharp, conv, yield)
+		}
+		return scanToken(str, next, last, "0123456789+-.eE", "", conv, yield)
+	case 'T':
+		return scanTime(str, next, last, sp.arg, yield)
+	case 'D':
+		return scanDuration(str, next, last, yield)
+	case 't':
+		return scanWords(str, boolWords, func(s string) (value, error) {
+			b, err := parseBool(s)
+			return value{kind: reflect.Bool, value: b}, err
+		}, yield)
+	}
+	return false, fmt.Errorf("unsupported verb %%%c", sp.verb)
+}
+
+// prefix returns the prefix of integer written by '#' flag or %O
+func (sp *spec) prefix() string {
+	if sp.verb == 'O' {
+		return "0o"
+	}
+	if !sp.sharp {
+		return ""
+	}
+	switch sp.verb {
+	case 'b':
+		return "0b"
+	case 'x':
+		return "0x"
+	case 'X':
+		return "0X"
+	}
+	return ""
+}
+
+// trimPrefix removes prefix following the sign of s
+func trimPrefix(s, prefix string) string {
+	if prefix == "" {
+		return s
+	}
+	sign := ""
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+	return sign + strings.TrimPrefix(s, prefix)
+}
+
+// scanText yields free text, shortest first.
+// The text at the end of format consumes the rest of str.
+// max is the max number of runes of the text, -1 means unlimited.
+func scanText(str, next string, last bool, max int, conv func(s string) value,
+	yield func(n int, v value) bool) (bool, error) {
+	if last {
+		if max >= 0 && utf8.RuneCountInString(str) > max {
+			return false, fmt.Errorf("longer than %d characters", max)
+		}
+		return yield(len(str), conv(str)), nil
+	}
+
+	for n := 0; n <= len(str); {
+		if next != "" {
+			i := strings.Index(str[n:], next)
+			if i == -1 {
+				break
+			}
+			n += i
+		}
+		if max >= 0 && utf8.RuneCountInString(str[:n]) > max {
+			return false, fmt.Errorf("longer than %d characters", max)
+		}
+		if yield(n, conv(str[:n])) {
+			return true, nil
+		}
+		if n == len(str) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(str[n:])
+		n += size
+	}
+
+	if next != "" && !strings.Contains(str, next) {
+		return false, notFound{next}
+	}
+	return false, nil
+}
+
+// scanToken yields the longest run of bytes in charset at the head of str
+// and its prefixes, longest first. The run can start with a sign and prefix.
+// The prefixes are tried only when the text following the verb
+// can start with a byte in charset, otherwise they never match.
+func scanToken(str, next string, last bool, charset, prefix string,
+	conv func(s string) (value, error), yield func(n int, v value) bool) (bool, error) {
+	l := 0
+	if l < len(str) && (str[0] == '+' || str[0] == '-') {
+		l++
+	}
+	if prefix != "" && strings.HasPrefix(str[l:], prefix) {
+		l += len(prefix)
+	}
+	head := l
+	for l < len(str) && strings.IndexByte(charset, str[l]) != -1 {
+		l++
+	}
+
+	if l == head {
+		return false, strconv.ErrSyntax
+	}
+
+	min := l
+	if !last && (next == "" || strings.IndexByte(charset, next[0]) != -1) {
+		min = head + 1
+	}
+
+	var firstErr error
+	converted := false
+	for n := l; n >= min; n-- {
+		v, err := conv(str[:n])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		converted = true
+		if yield(n, v) {
+			return true, nil
+		}
+	}
+	if !converted {
+		return false, firstErr
+	}
+	return false, nil
+}
+
+// scanFixed yields the decimal number which has prec digits after the point,
+// it's written by %.2f for instance.
+func scanFixed(str string, prec int, sharp bool,
+	conv func(s string) (value, error), yield func(n int, v value) bool) (bool, error) {
+	l := 0
+	if l < len(str) && (str[0] == '+' || str[0] == '-') {
+		l++
+	}
+	head := l
+	for l < len(str) && '0' <= str[l] && str[l] <= '9' {
+		l++
+	}
+	if l == head {
+		return false, strconv.ErrSyntax
+	}
+	if prec > 0 || sharp {
+		if l == len(str) || str[l] != '.' {
+			return false, strconv.ErrSyntax
+		}
+		l++
+	}
+	for i := 0; i < prec; i++ {
+		if l == len(str) || str[l] < '0' || '9' < str[l] {
+			return false, strconv.ErrSyntax
+		}
+		l++
+	}
+
+	v, err := conv(str[:l])
+	if err != nil {
+		return false, err
+	}
+	return yield(l, v), nil
+}
+
+var floatWords = []string{"NaN", "+Inf", "-Inf", "Inf"}
+
+var boolWords = []string{
+	"false", "FALSE", "False", "true", "TRUE", "True",
+	"f", "F", "t", "T", "0", "1",
+}
+
+// scanWords yields words which str starts with, in order of words
+func scanWords(str string, words []string,
+	conv func(s string) (value, error), yield func(n int, v value) bool) (bool, error) {
+	matched := false
+	for _, w := range words {
+		if !strings.HasPrefix(str, w) {
+			continue
+		}
+		matched = true
+		v, err := conv(w)
+		if err != nil {
+			return false, err
+		}
+		if yield(len(w), v) {
+			return true, nil
+		}
+	}
+	if !matched {
+		return false, strconv.ErrSyntax
+	}
+	return false, nil
+}
+
+// balanced returns the length of the slice, map or struct written by %v
+// at the head of str, like "[1 2]", "map[a:1]", "{a 1}" or "&{a 1}".
+// It's 0 when str doesn't start with them or the brackets are not closed.
+func balanced(str string) int {
+	i := 0
+	if strings.HasPrefix(str, "map[") {
+		i = len("map")
+	} else if strings.HasPrefix(str, "&{") {
+		i = len("&")
+	}
+	if i == len(str) || (str[i] != '[' && str[i] != '{') {
+		return 0
+	}
+	depth := 0
+	for ; i < len(str); i++ {
+		switch str[i] {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// parseValue returns the value of s formatted by %v
+func parseValue(s string) value {
+	if n, err := parseInteger(s, 10); err == nil {
+		return n
+	}
+	if b, err := parseBool(s); err == nil {
+		return value{kind: reflect.Bool, value: b}
+	}
+	if f, err := parseFloat(s); err == nil {
+		return value{kind: reflect.Float64, value: f}
+	}
+	if structText(s) {
+		return value{kind: reflect.Struct, value: parseStruct(s)}
+	}
+	return value{kind: reflect.String, value: s}
+}
+
+// parseHex returns the value of s written by %x.
+// s is the integer, or hex-encoded bytes when it's too large for uint64.
+func parseHex(s string) (value, error) {
+	n, err := parseInteger(s, 16)
+	if err == nil {
+		return n, nil
+	}
+	b, herr := hex.DecodeString(s)
+	if herr != nil {
+		return value{}, err
+	}
+	return value{kind: reflect.Slice, value: b}, nil
+}
+
+// scanSpacedHex yields the bytes separated by spaces like "68 65 6c"
+// at the head of str, longest first. Each byte can start with prefix.
+func scanSpacedHex(str, prefix string, yield func(n int, v value) bool) bool {
+	var ends []int
+	var b []byte
+	for i := 0; ; i++ {
+		if !strings.HasPrefix(str[i:], prefix) {
+			break
+		}
+		i += len(prefix)
+		if i+2 > len(str) || !isHexDigit(str[i]) || !isHexDigit(str[i+1]) {
+			break
+		}
+		c, _ := strconv.ParseUint(str[i:i+2], 16, 8)
+		b = append(b, byte(c))
+		i += 2
+		ends = append(ends, i)
+		if i == len(str) || str[i] != ' ' {
+			break
+		}
+	}
+
+	for k := len(ends); k >= 2; k-- {
+		if yield(ends[k-1], value{kind: reflect.Slice, value: b[:k:k]}) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// scanRune yields the character at the head of str, it's written by %c
+func scanRune(str string, yield func(n int, v value) bool) (bool, error) {
+	r, size := utf8.DecodeRuneInString(str)
+	if size == 0 || (r == utf8.RuneError && size == 1) {
+		return false, strconv.ErrSyntax
+	}
+	return yield(size, value{kind: reflect.Int32, value: r}), nil
+}
+
+// scanUnicode yields the character written by %U like "U+1F600".
+// sharp is true for %#U like "U+0041 'A'".
+func scanUnicode(str string, sharp bool, yield func(n int, v value) bool) (bool, error) {
+	if !strings.HasPrefix(str, "U+") {
+		return false, strconv.ErrSyntax
+	}
+	l := 2
+	for l < len(str) && l < 10 && strings.IndexByte("0123456789ABCDEF", str[l]) != -1 {
+		l++
+	}
+	if l < 6 {
+		return false, strconv.ErrSyntax
+	}
+	n, err := strconv.ParseInt(str[2:l], 16, 32)
+	if err != nil {
+		return false, err
+	}
+	r := rune(n)
+
+	if sharp && strconv.IsPrint(r) {
+		// U+0041 'A'
+		c := " '" + string(r) + "'"
+		if !strings.HasPrefix(str[l:], c) {
+			return false, strconv.ErrSyntax
+		}
+		l += len(c)
+	}
+	return yield(l, value{kind: reflect.Int32, value: r}), nil
+}
+
+// scanQuoted yields the Go string literal at the head of str, it's written by %q.
+// The string is double-quoted, or back-quoted by %#q. The single-quoted
+// character literal is read as rune.
+func scanQuoted(str string, yield func(n int, v value) bool) (bool, error) {
+	if str == "" {
+		return false, strconv.ErrSyntax
+	}
+
+	n := -1
+	switch q := str[0]; q {
+	case '`':
+		if i := strings.IndexByte(str[1:], '`'); i != -1 {
+			n = i + 2
+		}
+	case '"', '\'':
+		for i := 1; i < len(str); i++ {
+			if str[i] == '\\' {
+				i++
+				continue
+			}
+			if str[i] == q {
+				n = i + 1
+				break
+			}
+		}
+	}
+	if n == -1 {
+		return false, strconv.ErrSyntax
+	}
+
+	s, err := strconv.Unquote(str[:n])
+	if err != nil {
+		return false, err
+	}
+	if str[0] == '\'' {
+		r, _ := utf8.DecodeRuneInString(s)
+		return yield(n, value{kind: reflect.Int32, value: r}), nil
+	}
+	return yield(n, value{kind: reflect.String, value: s}), nil
+}
@@ -0,0 +1,266 @@
+// Copyright (C) 2018,2019 MizukiSonoko. All rights reserved.
+
+package goparse_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	goparse "github.com/MizukiSonoko/goparse/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_hex(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		for _, format := range []string{"id=%x", "id=%X", "id=%#x", "id=%#X", "id=%08x"} {
+			expected := 0xbeef
+			str := fmt.Sprintf(format, expected)
+			var res int
+			err := goparse.Parse(format, str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+			assert.Equal(t, expected, res)
+		}
+	})
+
+	t.Run("hex-encoded string", func(t *testing.T) {
+		for _, format := range []string{"msg=%x;", "msg=%X;", "msg=%#x;"} {
+			expected := "Hello, 世界"
+			str := fmt.Sprintf(format, expected)
+			var res string
+			err := goparse.Parse(format, str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+			assert.Equal(t, expected, res)
+		}
+	})
+
+	t.Run("bytes separated by spaces", func(t *testing.T) {
+		for _, format := range []string{"msg=% x;", "msg=% X;", "msg=% #x;", "msg=% #X", "% x"} {
+			expected := "Hello, 世界"
+			str := fmt.Sprintf(format, expected)
+			var s string
+			var b []byte
+			err := goparse.Parse(format, str).Insert(&s)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+			assert.Equal(t, expected, s)
+			err = goparse.Parse(format, str).Insert(&b)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+			assert.Equal(t, []byte(expected), b)
+		}
+
+		var s1, s2 string
+		err := goparse.Parse("% x % x", "68 69 6a 6b").Insert(&s1, &s2)
+		assert.NoError(t, err)
+		assert.Equal(t, "hij", s1)
+		assert.Equal(t, "k", s2)
+
+		var n int
+		err = goparse.Parse("n=% x", fmt.Sprintf("n=% x", 255)).Insert(&n)
+		assert.NoError(t, err)
+		assert.Equal(t, 255, n)
+
+		var b []byte
+		long := strings.Repeat("ab ", 5000)
+		err = goparse.Parse("% x|", long).Insert(&b)
+		assert.Error(t, err)
+		err = goparse.Parse("% x|", long[:len(long)-1]+"|").Insert(&b)
+		assert.NoError(t, err)
+		assert.Len(t, b, 5000)
+	})
+
+	t.Run("hex-encoded bytes longer than int", func(t *testing.T) {
+		format := "sha=%x"
+		expected := []byte("0123456789abcdef0123456789abcdef")
+		str := fmt.Sprintf(format, expected)
+		var res []byte
+		err := goparse.Parse(format, str).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("not hex", func(t *testing.T) {
+		var res int
+		err := goparse.Parse("id=%x", "id=xyz").Insert(&res)
+		assert.Error(t, err)
+	})
+}
+
+func TestParse_character(t *testing.T) {
+
+	t.Run("%c into rune", func(t *testing.T) {
+		format := "[%c%c]"
+		str := fmt.Sprintf(format, 'A', '世')
+		var r1, r2 rune
+		err := goparse.Parse(format, str).Insert(&r1, &r2)
+		assert.NoError(t, err)
+		assert.Equal(t, 'A', r1)
+		assert.Equal(t, '世', r2)
+	})
+
+	t.Run("%c into byte", func(t *testing.T) {
+		var b byte
+		err := goparse.Parse("grade %c", "grade A").Insert(&b)
+		assert.NoError(t, err)
+		assert.Equal(t, byte('A'), b)
+
+		err = goparse.Parse("grade %c", "grade 世").Insert(&b)
+		assert.Error(t, err)
+	})
+
+	t.Run("%c into string", func(t *testing.T) {
+		var s string
+		err := goparse.Parse("grade %c", "grade A").Insert(&s)
+		assert.NoError(t, err)
+		assert.Equal(t, "A", s)
+	})
+
+	t.Run("%U", func(t *testing.T) {
+		for _, format := range []string{"%U", "%#U", "char(%U)"} {
+			for _, expected := range []rune{'A', '世', 0x1F600, '\n'} {
+				str := fmt.Sprintf(format, expected)
+				var res rune
+				err := goparse.Parse(format, str).Insert(&res)
+				assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+				assert.Equal(t, expected, res)
+			}
+		}
+	})
+
+	t.Run("invalid %U", func(t *testing.T) {
+		var res rune
+		err := goparse.Parse("%U", "0041").Insert(&res)
+		assert.Error(t, err)
+	})
+}
+
+func TestParse_quoted(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		for _, format := range []string{"msg=%q", "msg=%q;", "msg=%+q;", "msg=%#q;", "%q%q"} {
+			for _, expected := range []string{
+				"hello",
+				`say "hi"`,
+				"tab\tnew line\n",
+				`back\slash`,
+				"日本語",
+				"",
+			} {
+				args := []interface{}{expected}
+				if format == "%q%q" {
+					args = append(args, expected)
+				}
+				str := fmt.Sprintf(format, args...)
+				r := goparse.Parse(format, str)
+				var res string
+				var err error
+				if format == "%q%q" {
+					var res2 string
+					err = r.Insert(&res, &res2)
+					assert.Equal(t, expected, res2)
+				} else {
+					err = r.Insert(&res)
+				}
+				assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+				assert.Equal(t, expected, res)
+			}
+		}
+	})
+
+	t.Run("the quoted string contains the following literal", func(t *testing.T) {
+		format := "%q, %q"
+		str := fmt.Sprintf(format, "a, b", "c")
+		var s1, s2 string
+		err := goparse.Parse(format, str).Insert(&s1, &s2)
+		assert.NoError(t, err)
+		assert.Equal(t, "a, b", s1)
+		assert.Equal(t, "c", s2)
+	})
+
+	t.Run("quoted character", func(t *testing.T) {
+		var r rune
+		err := goparse.Parse("char=%q", fmt.Sprintf("char=%q", '世')).Insert(&r)
+		assert.NoError(t, err)
+		assert.Equal(t, '世', r)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"msg=hello", `msg="unterminated`, `msg="bad \z escape"`} {
+			var res string
+			err := goparse.Parse("msg=%q", str).Insert(&res)
+			assert.Errorf(t, err, "Parse(%s,%s) not failed want fail", "msg=%q", str)
+		}
+	})
+}
+
+func TestParse_exponent(t *testing.T) {
+
+	t.Run("The opposite of Sprintf", func(t *testing.T) {
+		for _, format := range []string{
+			"%e", "%E", "%g", "%G", "%.3e", "%.10g", "%12.4E", "%-12g|", "%+e", "%F", "x=%e;",
+		} {
+			for _, expected := range []float64{
+				0, 1, -1, 123.456, -0.000123456, 6.02214076e23, 1.602e-19, 1.5e308,
+				math.Inf(1), math.Inf(-1),
+			} {
+				str := fmt.Sprintf(format, expected)
+				var res float64
+				err := goparse.Parse(format, str).Insert(&res)
+				assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+				assert.Equalf(t, str, fmt.Sprintf(format, res), "Parse(%s,%s)", format, str)
+			}
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		for _, format := range []string{"%e", "%g", "%f", "%.2f", "%8.3G"} {
+			str := fmt.Sprintf(format, math.NaN())
+			var res float64
+			err := goparse.Parse(format, str).Insert(&res)
+			assert.NoErrorf(t, err, "Parse(%s,%s) failed", format, str)
+			assert.True(t, math.IsNaN(res))
+		}
+	})
+
+	t.Run("the number is followed by e", func(t *testing.T) {
+		format := "%gea"
+		str := fmt.Sprintf(format, 1.5)
+		var res float64
+		err := goparse.Parse(format, str).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.5, res)
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		var res float32
+		err := goparse.Parse("%e", fmt.Sprintf("%e", float32(1.5e10))).Insert(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(1.5e10), res)
+
+		err = goparse.Parse("%e", "1e+39").Insert(&res)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "overflow")
+
+		err = goparse.Parse("%e", "-Inf").Insert(&res)
+		assert.NoError(t, err)
+		assert.True(t, math.IsInf(float64(res), -1))
+	})
+
+	t.Run("unsupported destination", func(t *testing.T) {
+		var res int
+		err := goparse.Parse("%e", "1.5e+00").Insert(&res)
+		assert.Error(t, err)
+	})
+}
+
+func ExampleParse_quoted() {
+	var user, query string
+	str := fmt.Sprintf("user=%q query=%q", "iori", `say "hello"`)
+	_ = goparse.Parse("user=%q query=%q", str).Insert(&user, &query)
+	fmt.Println(user)
+	fmt.Println(query)
+	// Output:
+	// iori
+	// say "hello"
+}